@@ -30,7 +30,14 @@ func InitDB(logger zerolog.Logger) {
 	}
 
 	logger.Info().Msg("Applying migrations")
-	DB.AutoMigrate(&User{})
+	if err := Migrate(DB); err != nil {
+		logger.Error().Err(err).Msg("Failed to apply migrations")
+	}
+}
+
+// Migrate creates or updates the tables of every model.
+func Migrate(conn *gorm.DB) error {
+	return conn.AutoMigrate(&User{}, &ContainerMonitor{}, &DbPm2Process{}, &Subscription{}, &PushSubscription{}, &VapidKey{}, &Incident{}, &RemediationAttempt{}, &EscalationPolicy{}, &MaintenanceWindow{}, &Silence{}, &RestartState{}, &RegistryCredential{}, &ComposeProject{}, &ContainerStats{})
 }
 
 func CloseDB(logger zerolog.Logger) {
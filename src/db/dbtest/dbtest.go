@@ -0,0 +1,39 @@
+// Package dbtest backs db.DB with an in-memory SQLite database in tests.
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Use points db.DB at a fresh, migrated in-memory database until the test ends.
+func Use(t testing.TB) {
+	t.Helper()
+
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %s", err)
+	}
+
+	// every connection to :memory: is a database of its own
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database: %s", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.Migrate(conn); err != nil {
+		t.Fatalf("failed to migrate test database: %s", err)
+	}
+
+	previous := db.DB
+	db.DB = conn
+	t.Cleanup(func() {
+		db.DB = previous
+		sqlDB.Close()
+	})
+}
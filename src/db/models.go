@@ -1,7 +1,365 @@
 package db
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type User struct {
 	ID    string `gorm:"primary_key"`
 	Name  string `gorm:"not null"`
 	Email string `gorm:"unique;not null"`
+	// Phone is in E.164 format and only used for alerts once verified
+	Phone           string
+	PhoneVerifiedAt *time.Time
+	// sha256 of the pending verification code and the wrong guesses made against it
+	PhoneCodeHash      string
+	PhoneCodeExpiresAt *time.Time
+	PhoneCodeAttempts  int
+}
+
+// ContainerMonitor is a docker container the watchdog keeps running, either
+// registered by hand or discovered through its labels. Tags group monitors
+// for maintenance windows and silences.
+// Ports and Mounts use the docker cli syntax ("8080:80/tcp", "data:/var/lib/data:ro").
+// Critical monitors also place voice calls when they go down, and incidents
+// of monitors with an EscalationPolicyID are escalated until acknowledged.
+// FailureThreshold and RecoveryThreshold override the global check
+// thresholds when set.
+type ContainerMonitor struct {
+	ID                 string   `gorm:"primary_key"`
+	Name               string   `gorm:"unique;not null"`
+	Image              string   `gorm:"not null"`
+	Env                []string `gorm:"serializer:json"`
+	Command            []string `gorm:"serializer:json"`
+	Ports              []string `gorm:"serializer:json"`
+	Mounts             []string `gorm:"serializer:json"`
+	Network            string
+	Labels             map[string]string `gorm:"serializer:json"`
+	Tags               []string          `gorm:"serializer:json"`
+	Enabled            bool              `gorm:"not null;index"`
+	Critical           bool              `gorm:"not null;default:false"`
+	EscalationPolicyID *string
+	FailureThreshold   int           `gorm:"not null;default:0"`
+	RecoveryThreshold  int           `gorm:"not null;default:0"`
+	RestartPolicy      RestartPolicy `gorm:"serializer:json"`
+	// UnhealthyGraceSeconds restarts containers failing their docker
+	// healthcheck for longer, 0 only alerts
+	UnhealthyGraceSeconds int `gorm:"not null;default:0"`
+	// AutoManaged monitors were discovered through the watchdog.enable label
+	AutoManaged bool `gorm:"not null;default:false"`
+	// StatRules alert on, and optionally restart, containers using too many resources
+	StatRules []StatRule `gorm:"serializer:json"`
+	// Entrypoint, User, WorkingDir, Healthcheck and DockerRestart are kept
+	// from discovered containers so they are recreated the same way
+	Entrypoint  []string `gorm:"serializer:json"`
+	User        string
+	WorkingDir  string
+	Healthcheck *Healthcheck `gorm:"serializer:json"`
+	// DockerRestart is the restart policy docker applies to the container
+	// itself, in the docker cli syntax, e.g. "unless-stopped" or "on-failure:3"
+	DockerRestart string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Healthcheck is a docker HEALTHCHECK, its durations are nanoseconds like in
+// the docker API.
+type Healthcheck struct {
+	Test        []string      `json:"test"`
+	Interval    time.Duration `json:"interval,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	StartPeriod time.Duration `json:"start_period,omitempty"`
+	Retries     int           `json:"retries,omitempty"`
+}
+
+const (
+	StatCPUPercent    = "cpu_percent"
+	StatMemoryPercent = "memory_percent"
+	StatMemoryBytes   = "memory_bytes"
+)
+
+// StatRule fires when Metric stays above Above for DurationMinutes, e.g.
+// memory_percent above 90 for 5 minutes.
+type StatRule struct {
+	Metric          string  `json:"metric"`
+	Above           float64 `json:"above"`
+	DurationMinutes int     `json:"duration_minutes"`
+	// Restart restarts the container once the rule fires, within its restart policy
+	Restart bool `json:"restart"`
+}
+
+// ContainerStats is a resource usage sample of a monitored container. The
+// network and block io counters are totals since the container started.
+type ContainerStats struct {
+	ID            uint      `gorm:"primary_key"`
+	MonitorID     string    `gorm:"not null;index:idx_container_stats_monitor"`
+	At            time.Time `gorm:"not null;index:idx_container_stats_monitor"`
+	CPUPercent    float64
+	MemoryUsage   int64
+	MemoryLimit   int64
+	MemoryPercent float64
+	NetworkRx     int64
+	NetworkTx     int64
+	BlockRead     int64
+	BlockWrite    int64
+}
+
+// DbPm2Process is a PM2 process the watchdog keeps running. Env, Interpreter
+// and Args are applied when the watchdog has to start the process itself.
+// Critical processes also place voice calls when they go down, thresholds
+// work as on ContainerMonitor.
+type DbPm2Process struct {
+	ID                 string              `gorm:"primary_key" json:"id"`
+	Name               string              `gorm:"unique;not null" json:"name"`
+	Command            string              `gorm:"not null" json:"command"`
+	Env                []map[string]string `gorm:"serializer:json" json:"env"`
+	PWD                string              `json:"pwd"`
+	Interpreter        string              `json:"interpreter"`
+	Args               []string            `gorm:"serializer:json" json:"args"`
+	Tags               []string            `gorm:"serializer:json" json:"tags"`
+	Enabled            bool                `gorm:"not null;index" json:"enabled"`
+	StartPolicy        string              `gorm:"not null;default:'auto'" json:"start_policy"`
+	Critical           bool                `gorm:"not null;default:false" json:"critical"`
+	EscalationPolicyID *string             `json:"escalation_policy_id"`
+	FailureThreshold   int                 `gorm:"not null;default:0" json:"failure_threshold"`
+	RecoveryThreshold  int                 `gorm:"not null;default:0" json:"recovery_threshold"`
+	RestartPolicy      RestartPolicy       `gorm:"serializer:json" json:"restart_policy"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+// ComposeProject is a docker compose project the watchdog keeps running. Its
+// containers are found through their com.docker.compose labels, an empty
+// Services list expects one running replica of every service ever found.
+type ComposeProject struct {
+	ID                 string           `gorm:"primary_key"`
+	Name               string           `gorm:"unique;not null"`
+	Services           []ComposeService `gorm:"serializer:json"`
+	Tags               []string         `gorm:"serializer:json"`
+	Enabled            bool             `gorm:"not null;index"`
+	Critical           bool             `gorm:"not null;default:false"`
+	EscalationPolicyID *string
+	FailureThreshold   int           `gorm:"not null;default:0"`
+	RecoveryThreshold  int           `gorm:"not null;default:0"`
+	RestartPolicy      RestartPolicy `gorm:"serializer:json"`
+	// Services found while Services is empty, so a service whose containers
+	// are all gone is still expected
+	DiscoveredServices []string `gorm:"serializer:json"`
+	// Last inspected container of every service as returned by the docker
+	// API, replicas are copied from it once all of them are gone
+	ServiceTemplates map[string]json.RawMessage `gorm:"serializer:json"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// ComposeService is a service of a compose project and the number of
+// replicas expected to run.
+type ComposeService struct {
+	Name     string `json:"name"`
+	Replicas int    `json:"replicas"`
+}
+
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// RestartPolicy decides when the watchdog remediates a down monitor. Zero
+// numbers use the global defaults, an empty Mode restarts always.
+type RestartPolicy struct {
+	Mode string `json:"mode,omitempty"`
+	// MaxRetries within WindowMinutes before remediation is exhausted, -1 retries forever
+	MaxRetries    int `json:"max_retries,omitempty"`
+	WindowMinutes int `json:"window_minutes,omitempty"`
+	// Backoff between attempts doubles from InitialBackoffSeconds up to MaxBackoffSeconds
+	InitialBackoffSeconds int `json:"initial_backoff_seconds,omitempty"`
+	MaxBackoffSeconds     int `json:"max_backoff_seconds,omitempty"`
+}
+
+const (
+	// StartPolicyAuto starts the process when it is missing from pm2
+	StartPolicyAuto = "auto"
+	// StartPolicyManual only restarts processes that already exist in pm2
+	StartPolicyManual = "manual"
+)
+
+func (DbPm2Process) TableName() string {
+	return "pm2_processes"
+}
+
+const (
+	MonitorTypeContainer = "container"
+	MonitorTypeProcess   = "process"
+	MonitorTypeCompose   = "compose"
+	// MonitorTypeAll subscribes to every monitor
+	MonitorTypeAll = "all"
+)
+
+// Subscription links a user to the monitors they want alerts for. A nil
+// MonitorID covers every monitor of MonitorType.
+type Subscription struct {
+	ID          string   `gorm:"primary_key"`
+	UserID      string   `gorm:"not null;index"`
+	User        User     `gorm:"constraint:OnDelete:CASCADE"`
+	MonitorType string   `gorm:"not null"`
+	MonitorID   *string  `gorm:"index"`
+	Channels    []string `gorm:"serializer:json"`
+	MinSeverity string   `gorm:"not null"`
+	// Endpoint and HMAC secret used by the webhook channel, the secret is
+	// encrypted with WATCHDOG_SECRET_KEY
+	WebhookURL             string
+	WebhookSecretEncrypted string
+	// Incoming webhook urls used by the chat channels
+	SlackWebhookURL   string
+	DiscordWebhookURL string
+	CreatedAt         time.Time
+}
+
+// PushSubscription is a browser registered for Web Push alerts.
+type PushSubscription struct {
+	ID        string `gorm:"primary_key"`
+	UserID    string `gorm:"not null;index"`
+	User      User   `gorm:"constraint:OnDelete:CASCADE"`
+	Endpoint  string `gorm:"unique;not null"`
+	P256dh    string `gorm:"not null"`
+	Auth      string `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// VapidKey is the generated Web Push key pair, used when the keys are not
+// provided through the environment. The private key is encrypted with
+// WATCHDOG_SECRET_KEY.
+type VapidKey struct {
+	ID                  uint `gorm:"primary_key"`
+	PublicKey           string
+	PrivateKeyEncrypted string
+	CreatedAt           time.Time
+}
+
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// Causes of an incident. Threshold incidents are opened by the resource rules
+// of a container that keeps running.
+const (
+	IncidentCauseDown      = "down"
+	IncidentCauseFlapping  = "flapping"
+	IncidentCauseThreshold = "threshold"
+)
+
+// Incident is a single outage of a monitor, from the first failed check
+// until it is seen running again.
+type Incident struct {
+	ID          string `gorm:"primary_key"`
+	MonitorType string `gorm:"not null;index:idx_incidents_monitor"`
+	MonitorID   string `gorm:"not null;index:idx_incidents_monitor"`
+	MonitorName string
+	Status      string `gorm:"not null;index"`
+	// Cause is what opened the incident, see IncidentCauseDown and the like
+	Cause string
+	// Reason is the docker or pm2 status that opened the incident
+	Reason string
+	// Failure is the latest failure class of a container, e.g. oom-killed
+	Failure         string
+	OpenedAt        time.Time `gorm:"not null;index"`
+	ResolvedAt      *time.Time
+	DowntimeSeconds int64
+	// Acknowledging an incident stops its escalation
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+	// EscalationStep is the number of escalation steps already notified
+	EscalationStep int                  `gorm:"not null;default:0"`
+	Attempts       []RemediationAttempt `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// RemediationAttempt is an action the watchdog took during an incident.
+type RemediationAttempt struct {
+	ID         uint   `gorm:"primary_key"`
+	IncidentID string `gorm:"not null;index"`
+	Action     string `gorm:"not null"`
+	Success    bool   `gorm:"not null"`
+	Error      string
+	At         time.Time `gorm:"not null"`
+}
+
+// EscalationPolicy notifies users step by step while an incident stays
+// unacknowledged.
+type EscalationPolicy struct {
+	ID        string           `gorm:"primary_key"`
+	Name      string           `gorm:"unique;not null"`
+	Steps     []EscalationStep `gorm:"serializer:json"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// EscalationStep notifies a user over a channel once the incident has been
+// open for DelayMinutes.
+type EscalationStep struct {
+	DelayMinutes int    `json:"delay_minutes"`
+	Channel      string `json:"channel"`
+	UserID       string `json:"user_id"`
+}
+
+// MaintenanceWindow suspends remediation and alerts for the monitors in its
+// scope. Windows without an RRule happen once, recurring windows repeat
+// until their rule or ExpiresAt ends them.
+type MaintenanceWindow struct {
+	ID       string    `gorm:"primary_key"`
+	Name     string    `gorm:"not null"`
+	StartsAt time.Time `gorm:"not null"`
+	// DurationMinutes is the length of every occurrence
+	DurationMinutes int `gorm:"not null"`
+	// RRule is a subset of RFC 5545 recurrence rules, e.g. "FREQ=WEEKLY;BYDAY=SU"
+	RRule string
+	// Timezone recurring windows follow, e.g. "Europe/Berlin"
+	Timezone string `gorm:"not null;default:'UTC'"`
+	// An empty scope covers every monitor
+	MonitorIDs []string `gorm:"serializer:json"`
+	Tags       []string `gorm:"serializer:json"`
+	ExpiresAt  *time.Time
+	CreatedBy  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Silence suspends remediation and alerts for the monitors in its scope
+// from StartsAt until it expires or is deleted.
+type Silence struct {
+	ID     string `gorm:"primary_key"`
+	Reason string `gorm:"not null"`
+	// An empty scope covers every monitor
+	MonitorIDs []string  `gorm:"serializer:json"`
+	Tags       []string  `gorm:"serializer:json"`
+	StartsAt   time.Time `gorm:"not null"`
+	ExpiresAt  *time.Time
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// RestartState is the remediation history of a monitor, kept so backoff and
+// retry limits survive a watchdog restart.
+type RestartState struct {
+	MonitorType   string      `gorm:"primary_key"`
+	MonitorID     string      `gorm:"primary_key"`
+	Attempts      []time.Time `gorm:"serializer:json"`
+	NextAttemptAt *time.Time
+	// ExhaustedAt is set once the retry limit was hit, until the monitor is up again
+	ExhaustedAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// RegistryCredential authenticates image pulls from a registry, e.g.
+// "ghcr.io" or "docker.io". The password is encrypted with WATCHDOG_SECRET_KEY.
+type RegistryCredential struct {
+	ID                string `gorm:"primary_key"`
+	Registry          string `gorm:"unique;not null"`
+	Username          string `gorm:"not null"`
+	PasswordEncrypted string `gorm:"not null"`
+	CreatedBy         string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
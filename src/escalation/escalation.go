@@ -0,0 +1,163 @@
+package escalation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/incident"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/rs/zerolog"
+)
+
+// RunEscalator periodically advances the escalation of open, unacknowledged
+// incidents until stop is closed.
+func RunEscalator(logger zerolog.Logger, stop chan struct{}) {
+	logger.Info().Msg("Escalator thread started")
+
+	HaltTime := os.Getenv("ESCALATION_HALT_TIME")
+	if HaltTime == "" {
+		HaltTime = "30"
+	}
+	haltDuration, err := strconv.Atoi(HaltTime)
+	if err != nil {
+		logger.Error().Msgf("Error converting ESCALATION_HALT_TIME to an integer: %s", err)
+		return
+	}
+	ticker := time.NewTicker(time.Second * time.Duration(haltDuration))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			Escalate(logger)
+
+		case <-stop:
+			logger.Info().Msg("Escalator thread exiting")
+			return
+		}
+	}
+}
+
+// Escalate notifies every escalation step that became due since the last run.
+// Incidents of monitors in maintenance or silenced wait until that ends.
+func Escalate(logger zerolog.Logger) {
+	var open []db.Incident
+	err := db.DB.Where("status = ? AND acknowledged_at IS NULL", db.IncidentOpen).Find(&open).Error
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load open incidents")
+		return
+	}
+
+	suppressions := maintenance.Load(logger)
+
+	for _, record := range open {
+		if suppressions.ForMonitor(record.MonitorType, record.MonitorID) != "" {
+			continue
+		}
+
+		policy, err := PolicyFor(record.MonitorType, record.MonitorID)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to load escalation policy of %s", record.MonitorName)
+			continue
+		}
+		if policy == nil {
+			continue
+		}
+
+		openFor := time.Since(record.OpenedAt)
+		step := record.EscalationStep
+		// a step that failed to send is retried on the next run instead of skipped
+		for step < len(policy.Steps) && openFor >= time.Duration(policy.Steps[step].DelayMinutes)*time.Minute {
+			if err := notifyStep(logger, record, policy.Steps[step], step); err != nil {
+				logger.Error().Err(err).Msgf("Failed to notify escalation step %d of incident %s", step+1, record.ID)
+				break
+			}
+			step++
+		}
+
+		if step != record.EscalationStep {
+			err := db.DB.Model(&db.Incident{}).Where("id = ? AND acknowledged_at IS NULL", record.ID).
+				Update("escalation_step", step).Error
+			if err != nil {
+				logger.Error().Err(err).Msgf("Failed to save escalation of incident %s", record.ID)
+				continue
+			}
+
+			record.EscalationStep = step
+			incident.Updated(record)
+		}
+	}
+}
+
+// PolicyFor returns the escalation policy attached to a monitor, or nil.
+func PolicyFor(monitorType string, monitorID string) (*db.EscalationPolicy, error) {
+	var policyID *string
+
+	switch monitorType {
+	case db.MonitorTypeContainer:
+		var monitor db.ContainerMonitor
+		if err := db.DB.Select("escalation_policy_id").Limit(1).Find(&monitor, "id = ?", monitorID).Error; err != nil {
+			return nil, err
+		}
+		policyID = monitor.EscalationPolicyID
+	case db.MonitorTypeProcess:
+		var monitor db.DbPm2Process
+		if err := db.DB.Select("escalation_policy_id").Limit(1).Find(&monitor, "id = ?", monitorID).Error; err != nil {
+			return nil, err
+		}
+		policyID = monitor.EscalationPolicyID
+	case db.MonitorTypeCompose:
+		var monitor db.ComposeProject
+		if err := db.DB.Select("escalation_policy_id").Limit(1).Find(&monitor, "id = ?", monitorID).Error; err != nil {
+			return nil, err
+		}
+		policyID = monitor.EscalationPolicyID
+	}
+
+	if policyID == nil {
+		return nil, nil
+	}
+
+	var policy db.EscalationPolicy
+	if err := db.DB.Limit(1).Find(&policy, "id = ?", *policyID).Error; err != nil {
+		return nil, err
+	}
+	if policy.ID == "" {
+		return nil, nil
+	}
+
+	return &policy, nil
+}
+
+// notifyStep sends one escalation step. Steps targeting a deleted user can
+// never be sent and are skipped rather than blocking the later steps.
+func notifyStep(logger zerolog.Logger, incident db.Incident, step db.EscalationStep, index int) error {
+	var user db.User
+	if err := db.DB.Limit(1).Find(&user, "id = ?", step.UserID).Error; err != nil {
+		return err
+	}
+	if user.ID == "" {
+		logger.Error().Msgf("Escalation step %d of incident %s targets unknown user %s", index+1, incident.ID, step.UserID)
+		return nil
+	}
+
+	event := notifier.NewEvent(notifier.EventEscalated, incident.MonitorType, incident.MonitorID, incident.MonitorName)
+	event.Status = incident.Reason
+	event.Downtime = time.Since(incident.OpenedAt)
+	event.Message = fmt.Sprintf("Incident %s is unacknowledged, escalation step %d", incident.ID, index+1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := notifier.SendTo(ctx, step.Channel, user, event); err != nil {
+		return err
+	}
+
+	logger.Warn().Msgf("Escalated incident %s to %s over %s", incident.ID, user.Name, step.Channel)
+	return nil
+}
@@ -0,0 +1,188 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/rs/zerolog"
+)
+
+// recorder records the users notified over a channel, failing while err is set.
+type recorder struct {
+	sent []string
+	err  error
+}
+
+func (r *recorder) Notify(ctx context.Context, recipient notifier.Recipient, event notifier.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.sent = append(r.sent, recipient.User.ID)
+	return nil
+}
+
+// setup stores a container monitor with a three step policy and an incident
+// of it opened ago, and returns the notifiers of the steps.
+func setup(t *testing.T, ago time.Duration) (email *recorder, sms *recorder, call *recorder) {
+	t.Helper()
+	dbtest.Use(t)
+
+	email, sms, call = &recorder{}, &recorder{}, &recorder{}
+	notifier.Register("test-email", email)
+	notifier.Register("test-sms", sms)
+	notifier.Register("test-call", call)
+
+	policyID := "policy"
+	records := []any{
+		&db.User{ID: "primary", Name: "Primary", Email: "primary@example.com"},
+		&db.User{ID: "secondary", Name: "Secondary", Email: "secondary@example.com"},
+		&db.EscalationPolicy{ID: policyID, Name: "on-call", Steps: []db.EscalationStep{
+			{DelayMinutes: 0, Channel: "test-email", UserID: "primary"},
+			{DelayMinutes: 5, Channel: "test-sms", UserID: "primary"},
+			{DelayMinutes: 15, Channel: "test-call", UserID: "secondary"},
+		}},
+		&db.ContainerMonitor{ID: "web", Name: "web", Image: "nginx", Enabled: true, EscalationPolicyID: &policyID},
+		&db.Incident{ID: "incident", MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web", Status: db.IncidentOpen, OpenedAt: time.Now().Add(-ago)},
+	}
+	for _, record := range records {
+		if err := db.DB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return email, sms, call
+}
+
+func escalationStep(t *testing.T) int {
+	t.Helper()
+
+	var record db.Incident
+	if err := db.DB.First(&record, "id = ?", "incident").Error; err != nil {
+		t.Fatal(err)
+	}
+	return record.EscalationStep
+}
+
+func openedAgo(t *testing.T, ago time.Duration) {
+	t.Helper()
+	db.DB.Model(&db.Incident{ID: "incident"}).Update("opened_at", time.Now().Add(-ago))
+}
+
+func TestEscalateSteps(t *testing.T) {
+	email, sms, call := setup(t, time.Minute)
+
+	steps := []struct {
+		name  string
+		ago   time.Duration
+		step  int
+		email int
+		sms   int
+		call  int
+	}{
+		{"first step is due at once", time.Minute, 1, 1, 0, 0},
+		{"nothing new is due", 4 * time.Minute, 1, 1, 0, 0},
+		{"second step after its delay", 6 * time.Minute, 2, 1, 1, 0},
+		{"third step after its delay", 16 * time.Minute, 3, 1, 1, 1},
+		{"every step was sent", time.Hour, 3, 1, 1, 1},
+	}
+
+	for _, step := range steps {
+		openedAgo(t, step.ago)
+		Escalate(zerolog.Nop())
+
+		if got := escalationStep(t); got != step.step {
+			t.Fatalf("%s: escalation step = %d, want %d", step.name, got, step.step)
+		}
+		if len(email.sent) != step.email || len(sms.sent) != step.sms || len(call.sent) != step.call {
+			t.Fatalf("%s: sent %d emails, %d sms, %d calls", step.name, len(email.sent), len(sms.sent), len(call.sent))
+		}
+	}
+
+	if call.sent[0] != "secondary" {
+		t.Fatalf("called %s, want the secondary", call.sent[0])
+	}
+}
+
+func TestEscalateCatchesUp(t *testing.T) {
+	email, sms, call := setup(t, 20*time.Minute)
+
+	Escalate(zerolog.Nop())
+
+	if got := escalationStep(t); got != 3 {
+		t.Fatalf("escalation step = %d, want every overdue step sent", got)
+	}
+	if len(email.sent) != 1 || len(sms.sent) != 1 || len(call.sent) != 1 {
+		t.Fatalf("sent %d emails, %d sms, %d calls", len(email.sent), len(sms.sent), len(call.sent))
+	}
+}
+
+func TestEscalateRetriesFailedSteps(t *testing.T) {
+	_, sms, call := setup(t, 20*time.Minute)
+	sms.err = errors.New("provider down")
+
+	Escalate(zerolog.Nop())
+	if got := escalationStep(t); got != 1 {
+		t.Fatalf("escalation step = %d, want to stop at the failed step", got)
+	}
+	if len(call.sent) != 0 {
+		t.Fatal("later steps were sent after a failed one")
+	}
+
+	sms.err = nil
+	Escalate(zerolog.Nop())
+	if got := escalationStep(t); got != 3 || len(sms.sent) != 1 || len(call.sent) != 1 {
+		t.Fatalf("escalation step = %d after the retry, sent %d sms and %d calls", got, len(sms.sent), len(call.sent))
+	}
+}
+
+func TestEscalateSkipsUnknownUsers(t *testing.T) {
+	_, sms, _ := setup(t, 6*time.Minute)
+	db.DB.Delete(&db.User{}, "id = ?", "primary")
+
+	Escalate(zerolog.Nop())
+
+	if got := escalationStep(t); got != 2 {
+		t.Fatalf("escalation step = %d, want the steps of the deleted user skipped", got)
+	}
+	if len(sms.sent) != 0 {
+		t.Fatal("notified a deleted user")
+	}
+}
+
+func TestEscalateStops(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{"acknowledged", func() {
+			db.DB.Model(&db.Incident{ID: "incident"}).Update("acknowledged_at", time.Now())
+		}},
+		{"resolved", func() {
+			db.DB.Model(&db.Incident{ID: "incident"}).Update("status", db.IncidentResolved)
+		}},
+		{"silenced", func() {
+			db.DB.Create(&db.Silence{ID: "silence", Reason: "deploy", MonitorIDs: []string{"web"}, StartsAt: time.Now().Add(-time.Minute)})
+		}},
+		{"no policy", func() {
+			db.DB.Model(&db.ContainerMonitor{ID: "web"}).Update("escalation_policy_id", nil)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			email, sms, call := setup(t, 20*time.Minute)
+			test.setup()
+
+			Escalate(zerolog.Nop())
+
+			if len(email.sent)+len(sms.sent)+len(call.sent) != 0 || escalationStep(t) != 0 {
+				t.Fatalf("escalated, step %d", escalationStep(t))
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Handler is called with every event delivered to a subscriber.
+type Handler func(Event)
+
+type subscriber struct {
+	name    string
+	events  chan Event
+	dropped atomic.Uint64
+}
+
+// Bus delivers published events to every subscriber. Each subscriber has its
+// own bounded buffer, events that do not fit are dropped so publishing never
+// blocks the check loops.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	buffer      int
+	logger      zerolog.Logger
+}
+
+func NewBus(logger zerolog.Logger, buffer int) *Bus {
+	return &Bus{buffer: buffer, logger: logger}
+}
+
+// Subscribe calls handler with every published event on its own goroutine,
+// until the returned function is called.
+func (b *Bus) Subscribe(name string, handler Handler) func() {
+	events, unsubscribe := b.Channel(name)
+
+	go func() {
+		for event := range events {
+			handler(event)
+		}
+	}()
+
+	return unsubscribe
+}
+
+// Channel returns a channel receiving every published event, closed by the
+// returned function.
+func (b *Bus) Channel(name string) (<-chan Event, func()) {
+	s := &subscriber{name: name, events: make(chan Event, b.buffer)}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			for i, current := range b.subscribers {
+				if current == s {
+					b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+					break
+				}
+			}
+			close(s.events)
+		})
+	}
+
+	return s.events, unsubscribe
+}
+
+// Publish hands event to every subscriber without waiting for them.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			// log the first drop and then every hundredth
+			if dropped := s.dropped.Add(1); dropped%100 == 1 {
+				b.logger.Warn().Msgf("Event subscriber %s is falling behind, %d events dropped", s.name, dropped)
+			}
+		}
+	}
+}
+
+// Dropped returns the number of events dropped per subscriber.
+func (b *Bus) Dropped() map[string]uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	dropped := map[string]uint64{}
+	for _, s := range b.subscribers {
+		dropped[s.name] += s.dropped.Load()
+	}
+
+	return dropped
+}
+
+var bus = NewBus(zerolog.Nop(), 256)
+
+// Setup replaces the default bus with one logging to logger and buffering
+// EVENT_BUFFER events per subscriber. Call it before subscribing.
+func Setup(logger zerolog.Logger) {
+	buffer := 256
+	if value := os.Getenv("EVENT_BUFFER"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			logger.Error().Msgf("Invalid EVENT_BUFFER %q, using %d", value, buffer)
+		} else {
+			buffer = size
+		}
+	}
+
+	bus = NewBus(logger, buffer)
+}
+
+// Subscribe subscribes handler to the default bus.
+func Subscribe(name string, handler Handler) func() {
+	return bus.Subscribe(name, handler)
+}
+
+// Channel subscribes a channel to the default bus.
+func Channel(name string) (<-chan Event, func()) {
+	return bus.Channel(name)
+}
+
+// Publish publishes event on the default bus.
+func Publish(event Event) {
+	bus.Publish(event)
+}
+
+// Dropped returns the events dropped per subscriber of the default bus.
+func Dropped() map[string]uint64 {
+	return bus.Dropped()
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+)
+
+type Kind string
+
+const (
+	KindCheckCompleted       Kind = "check_completed"
+	KindStateChanged         Kind = "state_changed"
+	KindRemediationStarted   Kind = "remediation_started"
+	KindRemediationFailed    Kind = "remediation_failed"
+	KindRemediationSucceeded Kind = "remediation_succeeded"
+	KindRemediationExhausted Kind = "remediation_exhausted"
+	KindIncidentUpdated      Kind = "incident_updated"
+	KindThresholdBreached    Kind = "threshold_breached"
+	KindThresholdCleared     Kind = "threshold_cleared"
+)
+
+// Event is published by the monitors, subscribers switch on the concrete type.
+type Event interface {
+	Kind() Kind
+	Origin() Source
+}
+
+// Source is the monitor an event is about, as seen by the check that
+// published it.
+type Source struct {
+	MonitorType string
+	MonitorID   string
+	MonitorName string
+	Critical    bool
+	// State of the monitor and since when it is in that state
+	State monitor.State
+	Since time.Time
+	// Status as reported by docker or pm2
+	Status string
+	// Failure classifies why a container stopped: clean-exit, crash,
+	// oom-killed or crash-loop. It is empty for running containers and processes.
+	Failure  string
+	ExitCode int
+	// Health is the docker healthcheck status and HealthLog the output of
+	// the latest healthcheck when it failed
+	Health    string
+	HealthLog string
+	// Drift describes a container running another image than its monitor expects
+	Drift       string
+	ContainerID string
+	PID         int
+	Time        time.Time
+}
+
+func (s Source) Origin() Source {
+	return s
+}
+
+// CheckCompleted is published for every check of a monitor.
+type CheckCompleted struct {
+	Source
+	Healthy bool
+	// Suppressed names the maintenance window or silence covering the monitor
+	Suppressed string
+}
+
+func (CheckCompleted) Kind() Kind { return KindCheckCompleted }
+
+// StateChanged is published when a check moves a monitor to another state,
+// and for the first check of every monitor.
+type StateChanged struct {
+	Source
+	// Previous is empty the first time a monitor is checked
+	Previous monitor.State
+	// Downtime is how long the monitor was down or flapping, set when it is up again
+	Downtime time.Duration
+	// Suppressed names the maintenance window or silence covering the monitor
+	Suppressed string
+}
+
+func (StateChanged) Kind() Kind { return KindStateChanged }
+
+// RemediationStarted is published before the watchdog acts on a down monitor.
+type RemediationStarted struct {
+	Source
+	Action string
+}
+
+func (RemediationStarted) Kind() Kind { return KindRemediationStarted }
+
+// RemediationFailed is published when an action on a monitor failed.
+type RemediationFailed struct {
+	Source
+	Action string
+	Error  string
+}
+
+func (RemediationFailed) Kind() Kind { return KindRemediationFailed }
+
+// RemediationSucceeded is published when an action on a monitor succeeded.
+type RemediationSucceeded struct {
+	Source
+	Action string
+}
+
+func (RemediationSucceeded) Kind() Kind { return KindRemediationSucceeded }
+
+// RemediationExhausted is published once when a monitor hit the retry limit
+// of its restart policy, or the watchdog gave up on it for Reason, it is not
+// remediated until it is up again.
+type RemediationExhausted struct {
+	Source
+	Attempts int
+	Window   time.Duration
+	// Reason is set when the watchdog gave up before the retry limit
+	Reason string
+}
+
+func (RemediationExhausted) Kind() Kind { return KindRemediationExhausted }
+
+// IncidentUpdated is published whenever an incident is opened, changed or
+// resolved. Only the monitor fields of Source are set.
+type IncidentUpdated struct {
+	Source
+	IncidentID string
+}
+
+func (IncidentUpdated) Kind() Kind { return KindIncidentUpdated }
+
+// ThresholdBreached is published once when a resource rule of a monitor
+// fires, e.g. memory_percent above 90 for 5 minutes.
+type ThresholdBreached struct {
+	Source
+	// Rule describes the rule, Value is the reading that fired it
+	Rule  string
+	Value float64
+	// Suppressed names the maintenance window or silence covering the monitor
+	Suppressed string
+}
+
+func (ThresholdBreached) Kind() Kind { return KindThresholdBreached }
+
+// ThresholdCleared is published when the reading of a fired rule is back
+// below its threshold. Firing is the number of rules of the monitor still firing.
+type ThresholdCleared struct {
+	Source
+	Rule       string
+	Value      float64
+	Firing     int
+	Suppressed string
+}
+
+func (ThresholdCleared) Kind() Kind { return KindThresholdCleared }
+
+// Remediate runs action on the monitor of source, publishing its start and
+// outcome. fn may update source, e.g. with the id of a new container.
+func Remediate(source *Source, action string, fn func() error) error {
+	source.Time = time.Now()
+	Publish(RemediationStarted{Source: *source, Action: action})
+
+	err := fn()
+
+	source.Time = time.Now()
+	if err != nil {
+		Publish(RemediationFailed{Source: *source, Action: action, Error: err.Error()})
+	} else {
+		Publish(RemediationSucceeded{Source: *source, Action: action})
+	}
+
+	return err
+}
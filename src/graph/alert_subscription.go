@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+	"github.com/PayCryps/WatchdogGo/src/utils"
+)
+
+func monitorExists(monitorType string, monitorID string) (bool, error) {
+	var count int64
+	var err error
+
+	switch monitorType {
+	case db.MonitorTypeContainer:
+		err = db.DB.Model(&db.ContainerMonitor{}).Where("id = ?", monitorID).Count(&count).Error
+	case db.MonitorTypeProcess:
+		err = db.DB.Model(&db.DbPm2Process{}).Where("id = ?", monitorID).Count(&count).Error
+	case db.MonitorTypeCompose:
+		err = db.DB.Model(&db.ComposeProject{}).Where("id = ?", monitorID).Count(&count).Error
+	default:
+		return false, fmt.Errorf("unknown monitor type %s", monitorType)
+	}
+
+	return count > 0, err
+}
+
+func subscriptionFromInput(user *db.User, input model.SubscribeInput) (*db.Subscription, error) {
+	subscription := &db.Subscription{
+		UserID:      user.ID,
+		User:        *user,
+		MonitorType: strings.ToLower(input.MonitorType.String()),
+		MinSeverity: strings.ToLower(model.SeverityInfo.String()),
+	}
+
+	if input.MinSeverity != nil {
+		subscription.MinSeverity = strings.ToLower(input.MinSeverity.String())
+	}
+
+	if len(input.Channels) == 0 {
+		return nil, fmt.Errorf("at least one channel is required")
+	}
+	for _, channel := range input.Channels {
+		subscription.Channels = append(subscription.Channels, strings.ToLower(channel.String()))
+	}
+
+	if utils.Contains(subscription.Channels, notifier.ChannelSMS) || utils.Contains(subscription.Channels, notifier.ChannelCall) {
+		if user.PhoneVerifiedAt == nil {
+			return nil, fmt.Errorf("verify a phone number before subscribing to sms or call alerts")
+		}
+	}
+
+	if input.WebhookURL != nil {
+		subscription.WebhookURL = *input.WebhookURL
+	}
+	if input.WebhookSecret != nil {
+		secret, err := encryptWebhookSecret(*input.WebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+		subscription.WebhookSecretEncrypted = secret
+	}
+	if utils.Contains(subscription.Channels, notifier.ChannelWebhook) || subscription.WebhookURL != "" {
+		if err := validateWebhookURL(subscription.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.SlackWebhookURL != nil {
+		subscription.SlackWebhookURL = *input.SlackWebhookURL
+	}
+	if utils.Contains(subscription.Channels, notifier.ChannelSlack) || subscription.SlackWebhookURL != "" {
+		if err := validateWebhookURL(subscription.SlackWebhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.DiscordWebhookURL != nil {
+		subscription.DiscordWebhookURL = *input.DiscordWebhookURL
+	}
+	if utils.Contains(subscription.Channels, notifier.ChannelDiscord) || subscription.DiscordWebhookURL != "" {
+		if err := validateWebhookURL(subscription.DiscordWebhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.MonitorID != nil && subscription.MonitorType != db.MonitorTypeAll {
+		exists, err := monitorExists(subscription.MonitorType, *input.MonitorID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%s monitor %s not found", subscription.MonitorType, *input.MonitorID)
+		}
+		subscription.MonitorID = input.MonitorID
+	}
+
+	return subscription, nil
+}
+
+// encryptWebhookSecret encrypts the HMAC secret of a webhook, an empty secret
+// leaves payloads unsigned.
+func encryptWebhookSecret(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+
+	encrypted, err := secrets.Encrypt(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt the webhook secret: %w", err)
+	}
+
+	return encrypted, nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", rawURL)
+	}
+
+	return nil
+}
+
+func toAlertSubscription(subscription db.Subscription) *model.AlertSubscription {
+	channels := []model.Channel{}
+	for _, channel := range subscription.Channels {
+		channels = append(channels, model.Channel(strings.ToUpper(channel)))
+	}
+
+	alertSubscription := &model.AlertSubscription{
+		ID:          subscription.ID,
+		User:        toUser(subscription.User),
+		MonitorType: model.MonitorType(strings.ToUpper(subscription.MonitorType)),
+		MonitorID:   subscription.MonitorID,
+		Channels:    channels,
+		MinSeverity: model.Severity(strings.ToUpper(subscription.MinSeverity)),
+		CreatedAt:   subscription.CreatedAt,
+	}
+	if subscription.WebhookURL != "" {
+		alertSubscription.WebhookURL = &subscription.WebhookURL
+	}
+	if subscription.SlackWebhookURL != "" {
+		alertSubscription.SlackWebhookURL = &subscription.SlackWebhookURL
+	}
+	if subscription.DiscordWebhookURL != "" {
+		alertSubscription.DiscordWebhookURL = &subscription.DiscordWebhookURL
+	}
+
+	return alertSubscription
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+type contextKey string
+
+const userIDKey contextKey = "UserID"
+
+// WithUserID stores the id of the calling user on the request context.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// currentUser loads the calling user set by the server middleware.
+func currentUser(ctx context.Context) (*db.User, error) {
+	userID, _ := ctx.Value(userIDKey).(string)
+	if userID == "" {
+		return nil, fmt.Errorf("not authenticated: missing user id")
+	}
+
+	var user db.User
+	if err := db.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("unknown user %s", userID)
+	}
+
+	return &user, nil
+}
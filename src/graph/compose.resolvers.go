@@ -0,0 +1,228 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/compose"
+	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateComposeProjectMonitor is the resolver for the createComposeProjectMonitor field.
+func (r *mutationResolver) CreateComposeProjectMonitor(ctx context.Context, input model.CreateComposeProjectMonitorInput) (*model.ComposeProjectMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	monitor := db.ComposeProject{
+		ID:       uuid.New().String(),
+		Name:     input.Name,
+		Services: composeServicesFromInput(input.Services),
+		Tags:     input.Tags,
+		Enabled:  true,
+	}
+	if input.Enabled != nil {
+		monitor.Enabled = *input.Enabled
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+
+	if err := validateComposeProject(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Create(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toComposeProjectMonitor(monitor), nil
+}
+
+// UpdateComposeProjectMonitor is the resolver for the updateComposeProjectMonitor field.
+func (r *mutationResolver) UpdateComposeProjectMonitor(ctx context.Context, id string, input model.UpdateComposeProjectMonitorInput) (*model.ComposeProjectMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.ComposeProject
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("compose project monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	if input.Name != nil {
+		monitor.Name = *input.Name
+	}
+	if input.Services != nil {
+		monitor.Services = composeServicesFromInput(input.Services)
+		// services removed from the project are forgotten
+		monitor.DiscoveredServices = nil
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.Tags != nil {
+		monitor.Tags = input.Tags
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+
+	if err := validateComposeProject(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toComposeProjectMonitor(monitor), nil
+}
+
+// SetComposeProjectMonitorEnabled is the resolver for the setComposeProjectMonitorEnabled field.
+func (r *mutationResolver) SetComposeProjectMonitorEnabled(ctx context.Context, id string, enabled bool) (*model.ComposeProjectMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.ComposeProject
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("compose project monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	monitor.Enabled = enabled
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toComposeProjectMonitor(monitor), nil
+}
+
+// DeleteComposeProjectMonitor is the resolver for the deleteComposeProjectMonitor field.
+func (r *mutationResolver) DeleteComposeProjectMonitor(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.ComposeProject{}, "id = ?", id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ComposeProjectMonitors is the resolver for the composeProjectMonitors field.
+func (r *queryResolver) ComposeProjectMonitors(ctx context.Context) ([]*model.ComposeProjectMonitor, error) {
+	var monitors []db.ComposeProject
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.ComposeProjectMonitor{}
+	for _, monitor := range monitors {
+		list = append(list, toComposeProjectMonitor(monitor))
+	}
+
+	return list, nil
+}
+
+// ComposeProjectMonitor is the resolver for the composeProjectMonitor field.
+func (r *queryResolver) ComposeProjectMonitor(ctx context.Context, id string) (*model.ComposeProjectMonitor, error) {
+	var monitor db.ComposeProject
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return toComposeProjectMonitor(monitor), nil
+}
+
+// ComposeProjects is the resolver for the composeProjects field.
+func (r *queryResolver) ComposeProjects(ctx context.Context) ([]*model.ComposeProjectState, error) {
+	var monitors []db.ComposeProject
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		return nil, err
+	}
+
+	containers, err := compose.ListProjectContainers(docker.CreateDockerClient())
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*model.ComposeProjectState{}
+	for _, monitor := range monitors {
+		list = append(list, toComposeProjectState(monitor, compose.Status(monitor, containers[monitor.Name])))
+	}
+
+	return list, nil
+}
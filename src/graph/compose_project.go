@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/compose"
+)
+
+// compose project names are lowercase, see the compose specification
+var composeProjectPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func validateComposeProject(monitor db.ComposeProject) error {
+	if !composeProjectPattern.MatchString(monitor.Name) {
+		return fmt.Errorf("invalid compose project name %q", monitor.Name)
+	}
+
+	seen := map[string]bool{}
+	for _, service := range monitor.Services {
+		if strings.TrimSpace(service.Name) == "" {
+			return fmt.Errorf("service name is required")
+		}
+		if seen[service.Name] {
+			return fmt.Errorf("service %s is listed twice", service.Name)
+		}
+		seen[service.Name] = true
+		if service.Replicas < 1 {
+			return fmt.Errorf("service %s needs at least one replica", service.Name)
+		}
+	}
+
+	if err := validateTags(monitor.Tags); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.DB.Model(&db.ComposeProject{}).Where("name = ? AND id <> ?", monitor.Name, monitor.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a compose project monitor named %q already exists", monitor.Name)
+	}
+
+	return nil
+}
+
+func composeServicesFromInput(input []*model.ComposeServiceInput) []db.ComposeService {
+	services := []db.ComposeService{}
+	for _, service := range input {
+		replicas := 1
+		if service.Replicas != nil {
+			replicas = int(*service.Replicas)
+		}
+		services = append(services, db.ComposeService{Name: service.Name, Replicas: replicas})
+	}
+
+	return services
+}
+
+func toComposeProjectMonitor(monitor db.ComposeProject) *model.ComposeProjectMonitor {
+	services := []*model.ComposeService{}
+	for _, service := range monitor.Services {
+		services = append(services, &model.ComposeService{Name: service.Name, Replicas: int32(service.Replicas)})
+	}
+
+	return &model.ComposeProjectMonitor{
+		ID:                 monitor.ID,
+		Name:               monitor.Name,
+		Services:           services,
+		Enabled:            monitor.Enabled,
+		Critical:           monitor.Critical,
+		Tags:               emptyIfNil(monitor.Tags),
+		EscalationPolicyID: monitor.EscalationPolicyID,
+		FailureThreshold:   thresholdOrNil(monitor.FailureThreshold),
+		RecoveryThreshold:  thresholdOrNil(monitor.RecoveryThreshold),
+		RestartPolicy:      toRestartPolicy(monitor.RestartPolicy),
+		CreatedAt:          monitor.CreatedAt,
+		UpdatedAt:          monitor.UpdatedAt,
+	}
+}
+
+func toComposeProjectState(monitor db.ComposeProject, status compose.ProjectStatus) *model.ComposeProjectState {
+	services := []*model.ComposeServiceState{}
+	for _, service := range status.Services {
+		containers := []string{}
+		for _, replica := range service.Containers {
+			if len(replica.Names) > 0 {
+				containers = append(containers, strings.TrimPrefix(replica.Names[0], "/"))
+			}
+		}
+
+		services = append(services, &model.ComposeServiceState{
+			Name:       service.Name,
+			Desired:    int32(service.Desired),
+			Running:    int32(service.Running),
+			Containers: containers,
+			Healthy:    service.Healthy(),
+		})
+	}
+
+	return &model.ComposeProjectState{
+		Monitor:  toComposeProjectMonitor(monitor),
+		Healthy:  status.Healthy(),
+		Summary:  status.Summary(),
+		Services: services,
+	}
+}
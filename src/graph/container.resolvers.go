@@ -0,0 +1,262 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateContainerMonitor is the resolver for the createContainerMonitor field.
+func (r *mutationResolver) CreateContainerMonitor(ctx context.Context, input model.CreateContainerMonitorInput) (*model.ContainerMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	monitor := db.ContainerMonitor{
+		ID:      uuid.New().String(),
+		Name:    input.Name,
+		Image:   input.Image,
+		Env:     input.Env,
+		Command: input.Command,
+		Ports:   input.Ports,
+		Mounts:  input.Mounts,
+		Labels:  labelsFromInput(input.Labels),
+		Tags:    input.Tags,
+		Enabled: true,
+	}
+	if input.Network != nil {
+		monitor.Network = *input.Network
+	}
+	if input.Enabled != nil {
+		monitor.Enabled = *input.Enabled
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+	if input.UnhealthyGraceSeconds != nil {
+		grace, err := threshold("unhealthyGraceSeconds", *input.UnhealthyGraceSeconds)
+		if err != nil {
+			return nil, err
+		}
+		monitor.UnhealthyGraceSeconds = grace
+	}
+	if input.StatRules != nil {
+		rules, err := statRulesFromInput(input.StatRules)
+		if err != nil {
+			return nil, err
+		}
+		monitor.StatRules = rules
+	}
+
+	if err := validateContainerMonitor(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Create(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toContainerMonitor(monitor, true), nil
+}
+
+// UpdateContainerMonitor is the resolver for the updateContainerMonitor field.
+func (r *mutationResolver) UpdateContainerMonitor(ctx context.Context, id string, input model.UpdateContainerMonitorInput) (*model.ContainerMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.ContainerMonitor
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("container monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	if input.Name != nil {
+		monitor.Name = *input.Name
+	}
+	if input.Image != nil {
+		monitor.Image = *input.Image
+	}
+	if input.Env != nil {
+		monitor.Env = input.Env
+	}
+	if input.Command != nil {
+		monitor.Command = input.Command
+	}
+	if input.Ports != nil {
+		monitor.Ports = input.Ports
+	}
+	if input.Mounts != nil {
+		monitor.Mounts = input.Mounts
+	}
+	if input.Network != nil {
+		monitor.Network = *input.Network
+	}
+	if input.Labels != nil {
+		monitor.Labels = labelsFromInput(input.Labels)
+	}
+
+	if input.Tags != nil {
+		monitor.Tags = input.Tags
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+	if input.UnhealthyGraceSeconds != nil {
+		grace, err := threshold("unhealthyGraceSeconds", *input.UnhealthyGraceSeconds)
+		if err != nil {
+			return nil, err
+		}
+		monitor.UnhealthyGraceSeconds = grace
+	}
+	if input.StatRules != nil {
+		rules, err := statRulesFromInput(input.StatRules)
+		if err != nil {
+			return nil, err
+		}
+		monitor.StatRules = rules
+	}
+
+	if err := validateContainerMonitor(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toContainerMonitor(monitor, true), nil
+}
+
+// SetContainerMonitorEnabled is the resolver for the setContainerMonitorEnabled field.
+func (r *mutationResolver) SetContainerMonitorEnabled(ctx context.Context, id string, enabled bool) (*model.ContainerMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.ContainerMonitor
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("container monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	monitor.Enabled = enabled
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toContainerMonitor(monitor, true), nil
+}
+
+// DeleteContainerMonitor is the resolver for the deleteContainerMonitor field.
+func (r *mutationResolver) DeleteContainerMonitor(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.ContainerMonitor{}, "id = ?", id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ContainerMonitors is the resolver for the containerMonitors field.
+func (r *queryResolver) ContainerMonitors(ctx context.Context) ([]*model.ContainerMonitor, error) {
+	var monitors []db.ContainerMonitor
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.ContainerMonitor{}
+	for _, monitor := range monitors {
+		list = append(list, toContainerMonitor(monitor, false))
+	}
+
+	return list, nil
+}
+
+// ContainerMonitor is the resolver for the containerMonitor field.
+func (r *queryResolver) ContainerMonitor(ctx context.Context, id string) (*model.ContainerMonitor, error) {
+	var monitor db.ContainerMonitor
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// env values may hold secrets
+	_, err := currentUser(ctx)
+
+	return toContainerMonitor(monitor, err == nil), nil
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
+	"github.com/distribution/reference"
+)
+
+// Same rule docker applies to container names
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+func validateContainerMonitor(monitor db.ContainerMonitor) error {
+	if !containerNamePattern.MatchString(monitor.Name) {
+		return fmt.Errorf("invalid container name %q", monitor.Name)
+	}
+
+	if _, err := reference.ParseNormalizedNamed(monitor.Image); err != nil {
+		return fmt.Errorf("invalid image reference %q: %w", monitor.Image, err)
+	}
+
+	for _, env := range monitor.Env {
+		if !strings.Contains(env, "=") || strings.HasPrefix(env, "=") {
+			return fmt.Errorf("invalid env %q: expected KEY=VALUE", env)
+		}
+	}
+
+	if _, _, err := docker.ParsePorts(monitor.Ports); err != nil {
+		return err
+	}
+
+	if _, err := docker.ParseMounts(monitor.Mounts); err != nil {
+		return err
+	}
+
+	if err := validateTags(monitor.Tags); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.DB.Model(&db.ContainerMonitor{}).Where("name = ? AND id <> ?", monitor.Name, monitor.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a container monitor named %q already exists", monitor.Name)
+	}
+
+	return nil
+}
+
+func labelsFromInput(input []*model.LabelInput) map[string]string {
+	labels := map[string]string{}
+	for _, label := range input {
+		labels[label.Key] = label.Value
+	}
+
+	return labels
+}
+
+func toLabels(labels map[string]string) []*model.Label {
+	list := []*model.Label{}
+	for key, value := range labels {
+		list = append(list, &model.Label{Key: key, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+
+	return list
+}
+
+func emptyIfNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
+
+// envNames strips the values off KEY=VALUE env entries since they may hold
+// secrets.
+func envNames(env []string) []string {
+	names := []string{}
+	for _, v := range env {
+		name, _, _ := strings.Cut(v, "=")
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func toContainerMonitor(monitor db.ContainerMonitor, withEnv bool) *model.ContainerMonitor {
+	env := envNames(monitor.Env)
+	if withEnv {
+		env = emptyIfNil(monitor.Env)
+	}
+
+	return &model.ContainerMonitor{
+		ID:                    monitor.ID,
+		Name:                  monitor.Name,
+		Image:                 monitor.Image,
+		Env:                   env,
+		Command:               emptyIfNil(monitor.Command),
+		Ports:                 emptyIfNil(monitor.Ports),
+		Mounts:                emptyIfNil(monitor.Mounts),
+		Network:               monitor.Network,
+		Labels:                toLabels(monitor.Labels),
+		Enabled:               monitor.Enabled,
+		Critical:              monitor.Critical,
+		Tags:                  emptyIfNil(monitor.Tags),
+		EscalationPolicyID:    monitor.EscalationPolicyID,
+		FailureThreshold:      thresholdOrNil(monitor.FailureThreshold),
+		RecoveryThreshold:     thresholdOrNil(monitor.RecoveryThreshold),
+		RestartPolicy:         toRestartPolicy(monitor.RestartPolicy),
+		UnhealthyGraceSeconds: thresholdOrNil(monitor.UnhealthyGraceSeconds),
+		AutoManaged:           monitor.AutoManaged,
+		StatRules:             toStatRules(monitor.StatRules),
+		CreatedAt:             monitor.CreatedAt,
+		UpdatedAt:             monitor.UpdatedAt,
+	}
+}
@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+// signedIn returns a context of a stored user, as set by the server middleware.
+func signedIn(t *testing.T) context.Context {
+	t.Helper()
+
+	user := db.User{ID: "user-1", Name: "Ada", Email: "ada@example.com"}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return WithUserID(context.Background(), user.ID)
+}
+
+func TestContainerMonitorMutationsNeedAUser(t *testing.T) {
+	dbtest.Use(t)
+	resolver := &Resolver{}
+	input := model.CreateContainerMonitorInput{Name: "host", Image: "alpine", Mounts: []string{"/:/host"}}
+
+	contexts := map[string]context.Context{
+		"anonymous":    context.Background(),
+		"unknown user": WithUserID(context.Background(), "nobody"),
+	}
+	for name, ctx := range contexts {
+		t.Run(name, func(t *testing.T) {
+			if _, err := resolver.Mutation().CreateContainerMonitor(ctx, input); err == nil {
+				t.Fatal("created a container monitor")
+			}
+			if _, err := resolver.Mutation().UpdateContainerMonitor(ctx, "web", model.UpdateContainerMonitorInput{}); err == nil {
+				t.Fatal("updated a container monitor")
+			}
+			if _, err := resolver.Mutation().SetContainerMonitorEnabled(ctx, "web", false); err == nil {
+				t.Fatal("disabled a container monitor")
+			}
+			if _, err := resolver.Mutation().DeleteContainerMonitor(ctx, "web"); err == nil {
+				t.Fatal("deleted a container monitor")
+			}
+		})
+	}
+
+	var count int64
+	db.DB.Model(&db.ContainerMonitor{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d container monitors stored", count)
+	}
+}
+
+func TestContainerMonitorEnv(t *testing.T) {
+	dbtest.Use(t)
+	resolver := &Resolver{}
+	ctx := signedIn(t)
+
+	created, err := resolver.Mutation().CreateContainerMonitor(ctx, model.CreateContainerMonitorInput{
+		Name:  "postgres",
+		Image: "postgres:15.0-alpine",
+		Env:   []string{"POSTGRES_USER=watchdog", "POSTGRES_PASSWORD=secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []string{"POSTGRES_USER=watchdog", "POSTGRES_PASSWORD=secret"}
+	keys := []string{"POSTGRES_USER", "POSTGRES_PASSWORD"}
+
+	if !slices.Equal(created.Env, values) {
+		t.Fatalf("created monitor env = %v", created.Env)
+	}
+
+	list, err := resolver.Query().ContainerMonitors(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ContainerMonitors() = %v, %v", list, err)
+	}
+	if !slices.Equal(list[0].Env, keys) {
+		t.Fatalf("listed monitor env = %v, want only the keys", list[0].Env)
+	}
+
+	single, err := resolver.Query().ContainerMonitor(ctx, created.ID)
+	if err != nil || !slices.Equal(single.Env, values) {
+		t.Fatalf("signed in ContainerMonitor() env = %v, %v", single.Env, err)
+	}
+
+	anonymous, err := resolver.Query().ContainerMonitor(context.Background(), created.ID)
+	if err != nil || !slices.Equal(anonymous.Env, keys) {
+		t.Fatalf("anonymous ContainerMonitor() env = %v, %v", anonymous.Env, err)
+	}
+}
@@ -0,0 +1,150 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/incident"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateEscalationPolicy is the resolver for the createEscalationPolicy field.
+func (r *mutationResolver) CreateEscalationPolicy(ctx context.Context, input model.EscalationPolicyInput) (*model.EscalationPolicy, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	policy := db.EscalationPolicy{ID: uuid.New().String()}
+	if err := escalationPolicyFromInput(&policy, input); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Create(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return toEscalationPolicy(policy), nil
+}
+
+// UpdateEscalationPolicy is the resolver for the updateEscalationPolicy field.
+func (r *mutationResolver) UpdateEscalationPolicy(ctx context.Context, id string, input model.EscalationPolicyInput) (*model.EscalationPolicy, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var policy db.EscalationPolicy
+	if err := db.DB.First(&policy, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("escalation policy %s not found", id)
+		}
+		return nil, err
+	}
+
+	if err := escalationPolicyFromInput(&policy, input); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Save(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return toEscalationPolicy(policy), nil
+}
+
+// DeleteEscalationPolicy is the resolver for the deleteEscalationPolicy field.
+func (r *mutationResolver) DeleteEscalationPolicy(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	deleted := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&db.ContainerMonitor{}).Where("escalation_policy_id = ?", id).Update("escalation_policy_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&db.DbPm2Process{}).Where("escalation_policy_id = ?", id).Update("escalation_policy_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&db.ComposeProject{}).Where("escalation_policy_id = ?", id).Update("escalation_policy_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&db.EscalationPolicy{}, "id = ?", id)
+		deleted = result.RowsAffected > 0
+
+		return result.Error
+	})
+
+	return deleted, err
+}
+
+// AcknowledgeIncident is the resolver for the acknowledgeIncident field.
+func (r *mutationResolver) AcknowledgeIncident(ctx context.Context, id string) (*model.Incident, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var record db.Incident
+	if err := db.DB.Preload("Attempts", func(tx *gorm.DB) *gorm.DB { return tx.Order("at") }).First(&record, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("incident %s not found", id)
+		}
+		return nil, err
+	}
+	if record.Status != db.IncidentOpen {
+		return nil, fmt.Errorf("incident %s is already resolved", id)
+	}
+
+	if err := incident.Acknowledge(&record, user.ID); err != nil {
+		return nil, err
+	}
+
+	return toIncident(record), nil
+}
+
+// ResolveIncident is the resolver for the resolveIncident field.
+func (r *mutationResolver) ResolveIncident(ctx context.Context, id string) (*model.Incident, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var record db.Incident
+	if err := db.DB.Preload("Attempts", func(tx *gorm.DB) *gorm.DB { return tx.Order("at") }).First(&record, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("incident %s not found", id)
+		}
+		return nil, err
+	}
+	if record.Status != db.IncidentOpen {
+		return nil, fmt.Errorf("incident %s is already resolved", id)
+	}
+
+	if err := incident.Close(&record); err != nil {
+		return nil, err
+	}
+
+	return toIncident(record), nil
+}
+
+// EscalationPolicies is the resolver for the escalationPolicies field.
+func (r *queryResolver) EscalationPolicies(ctx context.Context) ([]*model.EscalationPolicy, error) {
+	var policies []db.EscalationPolicy
+	if err := db.DB.Order("name").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.EscalationPolicy{}
+	for _, policy := range policies {
+		list = append(list, toEscalationPolicy(policy))
+	}
+
+	return list, nil
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/PayCryps/WatchdogGo/src/utils"
+)
+
+// Channels that only need the user, not a subscription
+var escalationChannels = []string{notifier.ChannelEmail, notifier.ChannelSMS, notifier.ChannelCall, notifier.ChannelPush}
+
+// escalationPolicyID checks that the policy exists. An empty id detaches the policy.
+func escalationPolicyID(id string) (*string, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	var count int64
+	if err := db.DB.Model(&db.EscalationPolicy{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("escalation policy %s not found", id)
+	}
+
+	return &id, nil
+}
+
+func escalationPolicyFromInput(policy *db.EscalationPolicy, input model.EscalationPolicyInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("escalation policy name is required")
+	}
+	if len(input.Steps) == 0 {
+		return fmt.Errorf("escalation policy needs at least one step")
+	}
+
+	var count int64
+	if err := db.DB.Model(&db.EscalationPolicy{}).Where("name = ? AND id <> ?", input.Name, policy.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("an escalation policy named %q already exists", input.Name)
+	}
+
+	steps := []db.EscalationStep{}
+	for i, step := range input.Steps {
+		channel := strings.ToLower(step.Channel.String())
+		if !utils.Contains(escalationChannels, channel) {
+			return fmt.Errorf("step %d: %s cannot be used for escalation", i+1, step.Channel)
+		}
+		if step.DelayMinutes < 0 {
+			return fmt.Errorf("step %d: delay must not be negative", i+1)
+		}
+		if i > 0 && int(step.DelayMinutes) < steps[i-1].DelayMinutes {
+			return fmt.Errorf("step %d: delays must not decrease", i+1)
+		}
+
+		var user db.User
+		if err := db.DB.First(&user, "id = ?", step.UserID).Error; err != nil {
+			return fmt.Errorf("step %d: unknown user %s", i+1, step.UserID)
+		}
+		if (channel == notifier.ChannelSMS || channel == notifier.ChannelCall) && user.PhoneVerifiedAt == nil {
+			return fmt.Errorf("step %d: %s has no verified phone number", i+1, user.Name)
+		}
+
+		steps = append(steps, db.EscalationStep{
+			DelayMinutes: int(step.DelayMinutes),
+			Channel:      channel,
+			UserID:       step.UserID,
+		})
+	}
+
+	policy.Name = input.Name
+	policy.Steps = steps
+
+	return nil
+}
+
+func toEscalationPolicy(policy db.EscalationPolicy) *model.EscalationPolicy {
+	steps := []*model.EscalationStep{}
+	for _, step := range policy.Steps {
+		steps = append(steps, &model.EscalationStep{
+			DelayMinutes: int32(step.DelayMinutes),
+			Channel:      model.Channel(strings.ToUpper(step.Channel)),
+			UserID:       step.UserID,
+		})
+	}
+
+	return &model.EscalationPolicy{
+		ID:        policy.ID,
+		Name:      policy.Name,
+		Steps:     steps,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+func toIncident(incident db.Incident) *model.Incident {
+	downtime := incident.DowntimeSeconds
+	if incident.ResolvedAt == nil {
+		downtime = int64(time.Since(incident.OpenedAt).Seconds())
+	}
+
+	attempts := []*model.RemediationAttempt{}
+	for _, attempt := range incident.Attempts {
+		a := &model.RemediationAttempt{
+			Action:  attempt.Action,
+			Success: attempt.Success,
+			At:      attempt.At,
+		}
+		if attempt.Error != "" {
+			a.Error = &attempt.Error
+		}
+		attempts = append(attempts, a)
+	}
+
+	result := &model.Incident{
+		ID:              incident.ID,
+		MonitorType:     model.MonitorType(strings.ToUpper(incident.MonitorType)),
+		MonitorID:       incident.MonitorID,
+		MonitorName:     incident.MonitorName,
+		Status:          model.IncidentStatus(strings.ToUpper(incident.Status)),
+		Reason:          incident.Reason,
+		OpenedAt:        incident.OpenedAt,
+		ResolvedAt:      incident.ResolvedAt,
+		DowntimeSeconds: int32(downtime),
+		AcknowledgedAt:  incident.AcknowledgedAt,
+		EscalationStep:  int32(incident.EscalationStep),
+		Attempts:        attempts,
+	}
+	if incident.Failure != "" {
+		result.Failure = &incident.Failure
+	}
+	if incident.AcknowledgedBy != "" {
+		result.AcknowledgedBy = &incident.AcknowledgedBy
+	}
+
+	return result
+}
@@ -0,0 +1,58 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"gorm.io/gorm"
+)
+
+// Incidents is the resolver for the incidents field.
+func (r *queryResolver) Incidents(ctx context.Context, monitorID *string, status *model.IncidentStatus, from *time.Time, to *time.Time) ([]*model.Incident, error) {
+	query := db.DB.Preload("Attempts", func(tx *gorm.DB) *gorm.DB { return tx.Order("at") })
+
+	if monitorID != nil {
+		query = query.Where("monitor_id = ?", *monitorID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", strings.ToLower(status.String()))
+	}
+	if from != nil {
+		query = query.Where("resolved_at IS NULL OR resolved_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("opened_at <= ?", *to)
+	}
+
+	var incidents []db.Incident
+	if err := query.Order("opened_at DESC").Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.Incident{}
+	for _, incident := range incidents {
+		list = append(list, toIncident(incident))
+	}
+
+	return list, nil
+}
+
+// Incident is the resolver for the incident field.
+func (r *queryResolver) Incident(ctx context.Context, id string) (*model.Incident, error) {
+	var incident db.Incident
+	if err := db.DB.Preload("Attempts", func(tx *gorm.DB) *gorm.DB { return tx.Order("at") }).First(&incident, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return toIncident(incident), nil
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"gorm.io/gorm"
+)
+
+// follow streams the bus events convert turns into a value until the client
+// goes away. convert returns nil for events the subscription skips.
+func follow[T any](ctx context.Context, convert func(events.Event) *T) <-chan *T {
+	feed, unsubscribe := events.Channel("graphql")
+	out := make(chan *T, 1)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-feed:
+				if !ok {
+					return
+				}
+
+				value := convert(event)
+				if value == nil {
+					continue
+				}
+
+				select {
+				case out <- value:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func forMonitor(monitorID *string, source events.Source) bool {
+	return monitorID == nil || *monitorID == source.MonitorID
+}
+
+func toMonitorState(state monitor.State) model.MonitorState {
+	return model.MonitorState(strings.ToUpper(string(state)))
+}
+
+func toMonitorStatus(changed events.StateChanged) *model.MonitorStatus {
+	status := &model.MonitorStatus{
+		MonitorType: model.MonitorType(strings.ToUpper(changed.MonitorType)),
+		MonitorID:   changed.MonitorID,
+		MonitorName: changed.MonitorName,
+		State:       toMonitorState(changed.State),
+		Status:      changed.Status,
+		At:          changed.Time,
+	}
+	if changed.Previous != "" {
+		previous := toMonitorState(changed.Previous)
+		status.PreviousState = &previous
+	}
+	if changed.ContainerID != "" {
+		status.ContainerID = &changed.ContainerID
+	}
+	if changed.PID != 0 {
+		pid := int32(changed.PID)
+		status.Pid = &pid
+	}
+	if changed.Suppressed != "" {
+		status.SuppressedBy = &changed.Suppressed
+	}
+
+	return status
+}
+
+func toRemediationLogEntry(source events.Source, action string, outcome model.RemediationOutcome, err string) *model.RemediationLogEntry {
+	entry := &model.RemediationLogEntry{
+		MonitorType: model.MonitorType(strings.ToUpper(source.MonitorType)),
+		MonitorID:   source.MonitorID,
+		MonitorName: source.MonitorName,
+		Action:      action,
+		Outcome:     outcome,
+		At:          source.Time,
+	}
+	if err != "" {
+		entry.Error = &err
+	}
+
+	return entry
+}
+
+func loadIncident(id string) (*db.Incident, error) {
+	var incident db.Incident
+	err := db.DB.Preload("Attempts", func(tx *gorm.DB) *gorm.DB { return tx.Order("at") }).First(&incident, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &incident, nil
+}
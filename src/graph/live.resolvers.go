@@ -0,0 +1,74 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+// MonitorStatusChanged is the resolver for the monitorStatusChanged field.
+func (r *subscriptionResolver) MonitorStatusChanged(ctx context.Context, monitorID *string) (<-chan *model.MonitorStatus, error) {
+	return follow(ctx, func(event events.Event) *model.MonitorStatus {
+		changed, ok := event.(events.StateChanged)
+		if !ok || !forMonitor(monitorID, changed.Source) {
+			return nil
+		}
+
+		return toMonitorStatus(changed)
+	}), nil
+}
+
+// IncidentUpdated is the resolver for the incidentUpdated field.
+func (r *subscriptionResolver) IncidentUpdated(ctx context.Context, monitorID *string) (<-chan *model.Incident, error) {
+	return follow(ctx, func(event events.Event) *model.Incident {
+		updated, ok := event.(events.IncidentUpdated)
+		if !ok || !forMonitor(monitorID, updated.Source) {
+			return nil
+		}
+
+		incident, err := loadIncident(updated.IncidentID)
+		if err != nil {
+			r.Logger.Error().Err(err).Msgf("Failed to load incident %s", updated.IncidentID)
+			return nil
+		}
+
+		return toIncident(*incident)
+	}), nil
+}
+
+// RemediationLog is the resolver for the remediationLog field.
+func (r *subscriptionResolver) RemediationLog(ctx context.Context, monitorID *string) (<-chan *model.RemediationLogEntry, error) {
+	return follow(ctx, func(event events.Event) *model.RemediationLogEntry {
+		if !forMonitor(monitorID, event.Origin()) {
+			return nil
+		}
+
+		switch e := event.(type) {
+		case events.RemediationStarted:
+			return toRemediationLogEntry(e.Source, e.Action, model.RemediationOutcomeStarted, "")
+		case events.RemediationFailed:
+			return toRemediationLogEntry(e.Source, e.Action, model.RemediationOutcomeFailed, e.Error)
+		case events.RemediationSucceeded:
+			return toRemediationLogEntry(e.Source, e.Action, model.RemediationOutcomeSucceeded, "")
+		case events.RemediationExhausted:
+			detail := fmt.Sprintf("gave up after %d restarts within %s", e.Attempts, e.Window)
+			if e.Reason != "" {
+				detail = "gave up: " + e.Reason
+			}
+			return toRemediationLogEntry(e.Source, "restart", model.RemediationOutcomeExhausted, detail)
+		}
+
+		return nil
+	}), nil
+}
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+)
+
+// next returns the next value of a subscription, failing after a second.
+func next[T any](t *testing.T, feed <-chan *T) *T {
+	t.Helper()
+
+	select {
+	case value, ok := <-feed:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return value
+	case <-time.After(time.Second):
+		t.Fatal("no value received")
+		return nil
+	}
+}
+
+func liveSource(monitorID string) events.Source {
+	return events.Source{MonitorType: db.MonitorTypeContainer, MonitorID: monitorID, MonitorName: monitorID, Time: time.Now()}
+}
+
+func TestMonitorStatusChanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitorID := "web"
+	feed, err := (&Resolver{}).Subscription().MonitorStatusChanged(ctx, &monitorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := liveSource("api")
+	other.State = monitor.StateDown
+	events.Publish(events.StateChanged{Source: other})
+	events.Publish(events.CheckCompleted{Source: liveSource("web")})
+
+	web := liveSource("web")
+	web.State = monitor.StateDown
+	web.ContainerID = "abc"
+	events.Publish(events.StateChanged{Source: web, Previous: monitor.StateUp, Suppressed: "deploy"})
+
+	status := next(t, feed)
+	if status.MonitorID != "web" || status.State != model.MonitorStateDown || status.MonitorType != model.MonitorTypeContainer {
+		t.Fatalf("received %+v", status)
+	}
+	if status.PreviousState == nil || *status.PreviousState != model.MonitorStateUp {
+		t.Fatalf("previous state = %v", status.PreviousState)
+	}
+	if status.ContainerID == nil || *status.ContainerID != "abc" || status.SuppressedBy == nil || *status.SuppressedBy != "deploy" {
+		t.Fatalf("received %+v", status)
+	}
+
+	// the feed is closed once the client goes away
+	cancel()
+	select {
+	case _, ok := <-feed:
+		if ok {
+			t.Fatal("received a value after the client went away")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestRemediationLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feed, err := (&Resolver{}).Subscription().RemediationLog(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events.Publish(events.RemediationStarted{Source: liveSource("web"), Action: "restart container"})
+	events.Publish(events.StateChanged{Source: liveSource("web")})
+	events.Publish(events.RemediationFailed{Source: liveSource("api"), Action: "restart container", Error: "no such container"})
+	events.Publish(events.RemediationExhausted{Source: liveSource("web"), Attempts: 5, Window: time.Hour})
+	events.Publish(events.RemediationExhausted{Source: liveSource("db"), Reason: "out of memory"})
+
+	tests := []struct {
+		monitorID string
+		outcome   model.RemediationOutcome
+		error     string
+	}{
+		{"web", model.RemediationOutcomeStarted, ""},
+		{"api", model.RemediationOutcomeFailed, "no such container"},
+		{"web", model.RemediationOutcomeExhausted, ""},
+		{"db", model.RemediationOutcomeExhausted, ""},
+	}
+	for _, test := range tests {
+		entry := next(t, feed)
+		if entry.MonitorID != test.monitorID || entry.Outcome != test.outcome {
+			t.Fatalf("received %+v, want %s %s", entry, test.monitorID, test.outcome)
+		}
+		if test.error != "" && (entry.Error == nil || *entry.Error != test.error) {
+			t.Fatalf("error = %v, want %s", entry.Error, test.error)
+		}
+	}
+}
+
+func TestIncidentUpdated(t *testing.T) {
+	dbtest.Use(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	record := db.Incident{ID: "incident", MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web", Status: db.IncidentOpen, Reason: "exited", OpenedAt: time.Now()}
+	if err := db.DB.Create(&record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	monitorID := "web"
+	feed, err := (&Resolver{}).Subscription().IncidentUpdated(ctx, &monitorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events.Publish(events.IncidentUpdated{Source: liveSource("web"), IncidentID: "deleted"})
+	events.Publish(events.IncidentUpdated{Source: liveSource("api"), IncidentID: "incident"})
+	events.Publish(events.IncidentUpdated{Source: liveSource("web"), IncidentID: "incident"})
+
+	incident := next(t, feed)
+	if incident.ID != "incident" || incident.MonitorID != "web" {
+		t.Fatalf("received %+v", incident)
+	}
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+)
+
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" || tag != strings.TrimSpace(tag) {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+
+	return nil
+}
+
+// validateScope checks that every monitor of a window or silence exists.
+func validateScope(monitorIDs []string, tags []string) error {
+	for _, monitorID := range monitorIDs {
+		container, err := monitorExists(db.MonitorTypeContainer, monitorID)
+		if err != nil {
+			return err
+		}
+		process, err := monitorExists(db.MonitorTypeProcess, monitorID)
+		if err != nil {
+			return err
+		}
+		project, err := monitorExists(db.MonitorTypeCompose, monitorID)
+		if err != nil {
+			return err
+		}
+		if !container && !process && !project {
+			return fmt.Errorf("monitor %s not found", monitorID)
+		}
+	}
+
+	return validateTags(tags)
+}
+
+func maintenanceWindowFromInput(window *db.MaintenanceWindow, input model.MaintenanceWindowInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("maintenance window name is required")
+	}
+	if err := validateScope(input.MonitorIds, input.Tags); err != nil {
+		return err
+	}
+
+	window.Name = input.Name
+	window.StartsAt = input.StartsAt
+	window.DurationMinutes = int(input.DurationMinutes)
+	window.RRule = ""
+	if input.Rrule != nil {
+		window.RRule = strings.TrimSpace(*input.Rrule)
+	}
+	window.Timezone = "UTC"
+	if input.Timezone != nil && *input.Timezone != "" {
+		window.Timezone = *input.Timezone
+	}
+	window.MonitorIDs = input.MonitorIds
+	window.Tags = input.Tags
+	window.ExpiresAt = input.ExpiresAt
+
+	return maintenance.ValidateWindow(*window)
+}
+
+func toMaintenanceWindow(window db.MaintenanceWindow) *model.MaintenanceWindow {
+	result := &model.MaintenanceWindow{
+		ID:              window.ID,
+		Name:            window.Name,
+		StartsAt:        window.StartsAt,
+		DurationMinutes: int32(window.DurationMinutes),
+		Timezone:        window.Timezone,
+		MonitorIds:      emptyIfNil(window.MonitorIDs),
+		Tags:            emptyIfNil(window.Tags),
+		ExpiresAt:       window.ExpiresAt,
+		Active:          maintenance.WindowActive(window, time.Now()),
+		CreatedAt:       window.CreatedAt,
+		UpdatedAt:       window.UpdatedAt,
+	}
+	if window.RRule != "" {
+		result.Rrule = &window.RRule
+	}
+	if window.CreatedBy != "" {
+		result.CreatedBy = &window.CreatedBy
+	}
+
+	return result
+}
+
+func toSilence(silence db.Silence) *model.Silence {
+	result := &model.Silence{
+		ID:         silence.ID,
+		Reason:     silence.Reason,
+		MonitorIds: emptyIfNil(silence.MonitorIDs),
+		Tags:       emptyIfNil(silence.Tags),
+		StartsAt:   silence.StartsAt,
+		ExpiresAt:  silence.ExpiresAt,
+		Active:     maintenance.SilenceActive(silence, time.Now()),
+		CreatedAt:  silence.CreatedAt,
+	}
+	if silence.CreatedBy != "" {
+		result.CreatedBy = &silence.CreatedBy
+	}
+
+	return result
+}
@@ -0,0 +1,180 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateMaintenanceWindow is the resolver for the createMaintenanceWindow field.
+func (r *mutationResolver) CreateMaintenanceWindow(ctx context.Context, input model.MaintenanceWindowInput) (*model.MaintenanceWindow, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	window := db.MaintenanceWindow{ID: uuid.New().String(), CreatedBy: user.ID}
+	if err := maintenanceWindowFromInput(&window, input); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Create(&window).Error; err != nil {
+		return nil, err
+	}
+
+	return toMaintenanceWindow(window), nil
+}
+
+// UpdateMaintenanceWindow is the resolver for the updateMaintenanceWindow field.
+func (r *mutationResolver) UpdateMaintenanceWindow(ctx context.Context, id string, input model.MaintenanceWindowInput) (*model.MaintenanceWindow, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var window db.MaintenanceWindow
+	if err := db.DB.First(&window, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("maintenance window %s not found", id)
+		}
+		return nil, err
+	}
+
+	if err := maintenanceWindowFromInput(&window, input); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Save(&window).Error; err != nil {
+		return nil, err
+	}
+
+	return toMaintenanceWindow(window), nil
+}
+
+// DeleteMaintenanceWindow is the resolver for the deleteMaintenanceWindow field.
+func (r *mutationResolver) DeleteMaintenanceWindow(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.MaintenanceWindow{}, "id = ?", id)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateSilence is the resolver for the createSilence field.
+func (r *mutationResolver) CreateSilence(ctx context.Context, input model.SilenceInput) (*model.Silence, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input.Reason) == "" {
+		return nil, fmt.Errorf("silence reason is required")
+	}
+	if err := validateScope(input.MonitorIds, input.Tags); err != nil {
+		return nil, err
+	}
+
+	silence := db.Silence{
+		ID:         uuid.New().String(),
+		Reason:     input.Reason,
+		MonitorIDs: input.MonitorIds,
+		Tags:       input.Tags,
+		StartsAt:   time.Now(),
+		ExpiresAt:  input.ExpiresAt,
+		CreatedBy:  user.ID,
+	}
+	if input.StartsAt != nil {
+		silence.StartsAt = *input.StartsAt
+	}
+	if silence.ExpiresAt != nil && !silence.ExpiresAt.After(silence.StartsAt) {
+		return nil, fmt.Errorf("silence must expire after it starts")
+	}
+
+	if err := db.DB.Create(&silence).Error; err != nil {
+		return nil, err
+	}
+
+	return toSilence(silence), nil
+}
+
+// ExpireSilence is the resolver for the expireSilence field.
+func (r *mutationResolver) ExpireSilence(ctx context.Context, id string) (*model.Silence, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var silence db.Silence
+	if err := db.DB.First(&silence, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("silence %s not found", id)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if silence.ExpiresAt == nil || silence.ExpiresAt.After(now) {
+		silence.ExpiresAt = &now
+		if err := db.DB.Save(&silence).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return toSilence(silence), nil
+}
+
+// DeleteSilence is the resolver for the deleteSilence field.
+func (r *mutationResolver) DeleteSilence(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.Silence{}, "id = ?", id)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// MaintenanceWindows is the resolver for the maintenanceWindows field.
+func (r *queryResolver) MaintenanceWindows(ctx context.Context) ([]*model.MaintenanceWindow, error) {
+	var windows []db.MaintenanceWindow
+	if err := db.DB.Order("starts_at").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.MaintenanceWindow{}
+	for _, window := range windows {
+		list = append(list, toMaintenanceWindow(window))
+	}
+
+	return list, nil
+}
+
+// Silences is the resolver for the silences field.
+func (r *queryResolver) Silences(ctx context.Context, active *bool) ([]*model.Silence, error) {
+	var silences []db.Silence
+	if err := db.DB.Order("starts_at DESC").Find(&silences).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := []*model.Silence{}
+	for _, silence := range silences {
+		if active != nil && maintenance.SilenceActive(silence, now) != *active {
+			continue
+		}
+		list = append(list, toSilence(silence))
+	}
+
+	return list, nil
+}
@@ -2,19 +2,951 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type AlertSubscription struct {
+	ID          string      `json:"id"`
+	User        *User       `json:"user"`
+	MonitorType MonitorType `json:"monitorType"`
+	// null when the subscription covers every monitor of monitorType
+	MonitorID         *string   `json:"monitorId,omitempty"`
+	Channels          []Channel `json:"channels"`
+	MinSeverity       Severity  `json:"minSeverity"`
+	WebhookURL        *string   `json:"webhookUrl,omitempty"`
+	SlackWebhookURL   *string   `json:"slackWebhookUrl,omitempty"`
+	DiscordWebhookURL *string   `json:"discordWebhookUrl,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+type ComposeProjectMonitor struct {
+	ID string `json:"id"`
+	// compose project name, as in the com.docker.compose.project label
+	Name string `json:"name"`
+	// expected services, empty expects one replica of every service ever found
+	Services []*ComposeService `json:"services"`
+	Enabled  bool              `json:"enabled"`
+	Critical bool              `json:"critical"`
+	// groups monitors for maintenance windows and silences
+	Tags               []string `json:"tags"`
+	EscalationPolicyID *string  `json:"escalationPolicyId,omitempty"`
+	// failed checks before the monitor is down, null uses the global FAILURE_THRESHOLD
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// passed checks before a down monitor is up, null uses the global RECOVERY_THRESHOLD
+	RecoveryThreshold *int32         `json:"recoveryThreshold,omitempty"`
+	RestartPolicy     *RestartPolicy `json:"restartPolicy"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+type ComposeProjectState struct {
+	Monitor *ComposeProjectMonitor `json:"monitor"`
+	// whether every service runs its desired replicas
+	Healthy bool `json:"healthy"`
+	// unhealthy services with their running replicas, e.g. web 1/2
+	Summary  string                 `json:"summary"`
+	Services []*ComposeServiceState `json:"services"`
+}
+
+type ComposeService struct {
+	Name string `json:"name"`
+	// running replicas expected
+	Replicas int32 `json:"replicas"`
+}
+
+type ComposeServiceInput struct {
+	Name string `json:"name"`
+	// defaults to 1
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+type ComposeServiceState struct {
+	Name    string `json:"name"`
+	Desired int32  `json:"desired"`
+	Running int32  `json:"running"`
+	// names of the containers of the service, stopped ones included
+	Containers []string `json:"containers"`
+	Healthy    bool     `json:"healthy"`
+}
+
+type ContainerMonitor struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// KEY=VALUE, only the keys unless a signed in user fetches a single monitor since values may hold secrets
+	Env      []string `json:"env"`
+	Command  []string `json:"command"`
+	Ports    []string `json:"ports"`
+	Mounts   []string `json:"mounts"`
+	Network  string   `json:"network"`
+	Labels   []*Label `json:"labels"`
+	Enabled  bool     `json:"enabled"`
+	Critical bool     `json:"critical"`
+	// groups monitors for maintenance windows and silences
+	Tags               []string `json:"tags"`
+	EscalationPolicyID *string  `json:"escalationPolicyId,omitempty"`
+	// failed checks before the monitor is down, null uses the global FAILURE_THRESHOLD
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// passed checks before a down monitor is up, null uses the global RECOVERY_THRESHOLD
+	RecoveryThreshold *int32         `json:"recoveryThreshold,omitempty"`
+	RestartPolicy     *RestartPolicy `json:"restartPolicy"`
+	// seconds a container may fail its docker healthcheck before it is restarted, null only alerts
+	UnhealthyGraceSeconds *int32 `json:"unhealthyGraceSeconds,omitempty"`
+	// discovered through the watchdog.enable label, disable it rather than deleting it to stop monitoring
+	AutoManaged bool        `json:"autoManaged"`
+	StatRules   []*StatRule `json:"statRules"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// resource usage of a container, network and block io are totals in bytes since it started
+type ContainerStatsSample struct {
+	At            time.Time `json:"at"`
+	CPUPercent    float64   `json:"cpuPercent"`
+	MemoryUsage   float64   `json:"memoryUsage"`
+	MemoryLimit   float64   `json:"memoryLimit"`
+	MemoryPercent float64   `json:"memoryPercent"`
+	NetworkRx     float64   `json:"networkRx"`
+	NetworkTx     float64   `json:"networkTx"`
+	BlockRead     float64   `json:"blockRead"`
+	BlockWrite    float64   `json:"blockWrite"`
+}
+
+type CreateComposeProjectMonitorInput struct {
+	Name               string                 `json:"name"`
+	Services           []*ComposeServiceInput `json:"services,omitempty"`
+	Enabled            *bool                  `json:"enabled,omitempty"`
+	Critical           *bool                  `json:"critical,omitempty"`
+	Tags               []string               `json:"tags,omitempty"`
+	EscalationPolicyID *string                `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32              `json:"recoveryThreshold,omitempty"`
+	RestartPolicy     *RestartPolicyInput `json:"restartPolicy,omitempty"`
+}
+
+type CreateContainerMonitorInput struct {
+	Name               string        `json:"name"`
+	Image              string        `json:"image"`
+	Env                []string      `json:"env,omitempty"`
+	Command            []string      `json:"command,omitempty"`
+	Ports              []string      `json:"ports,omitempty"`
+	Mounts             []string      `json:"mounts,omitempty"`
+	Network            *string       `json:"network,omitempty"`
+	Labels             []*LabelInput `json:"labels,omitempty"`
+	Enabled            *bool         `json:"enabled,omitempty"`
+	Critical           *bool         `json:"critical,omitempty"`
+	Tags               []string      `json:"tags,omitempty"`
+	EscalationPolicyID *string       `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32 `json:"recoveryThreshold,omitempty"`
+	// replaces the whole restart policy
+	RestartPolicy *RestartPolicyInput `json:"restartPolicy,omitempty"`
+	// 0 never restarts unhealthy containers
+	UnhealthyGraceSeconds *int32 `json:"unhealthyGraceSeconds,omitempty"`
+	// replaces the resource rules
+	StatRules []*StatRuleInput `json:"statRules,omitempty"`
+}
+
+type CreateProcessMonitorInput struct {
+	Name               string         `json:"name"`
+	Command            string         `json:"command"`
+	Pwd                *string        `json:"pwd,omitempty"`
+	Env                []*EnvVarInput `json:"env,omitempty"`
+	Interpreter        *string        `json:"interpreter,omitempty"`
+	Args               []string       `json:"args,omitempty"`
+	Enabled            *bool          `json:"enabled,omitempty"`
+	Critical           *bool          `json:"critical,omitempty"`
+	Tags               []string       `json:"tags,omitempty"`
+	StartPolicy        *StartPolicy   `json:"startPolicy,omitempty"`
+	EscalationPolicyID *string        `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32 `json:"recoveryThreshold,omitempty"`
+	// replaces the whole restart policy
+	RestartPolicy *RestartPolicyInput `json:"restartPolicy,omitempty"`
+}
+
 type CreateUserInput struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
 
+type EnvVar struct {
+	Key string `json:"key"`
+	// only returned to signed in users fetching a single monitor, values may hold secrets
+	Value *string `json:"value,omitempty"`
+}
+
+type EnvVarInput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type EscalationPolicy struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Steps     []*EscalationStep `json:"steps"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+type EscalationPolicyInput struct {
+	Name  string                 `json:"name"`
+	Steps []*EscalationStepInput `json:"steps"`
+}
+
+type EscalationStep struct {
+	// minutes after the incident opened
+	DelayMinutes int32   `json:"delayMinutes"`
+	Channel      Channel `json:"channel"`
+	UserID       string  `json:"userId"`
+}
+
+type EscalationStepInput struct {
+	DelayMinutes int32 `json:"delayMinutes"`
+	// EMAIL, SMS, CALL or PUSH
+	Channel Channel `json:"channel"`
+	UserID  string  `json:"userId"`
+}
+
+type Incident struct {
+	ID          string         `json:"id"`
+	MonitorType MonitorType    `json:"monitorType"`
+	MonitorID   string         `json:"monitorId"`
+	MonitorName string         `json:"monitorName"`
+	Status      IncidentStatus `json:"status"`
+	// docker or pm2 status that opened the incident
+	Reason string `json:"reason"`
+	// latest failure class of a container: clean-exit, crash, oom-killed or crash-loop
+	Failure    *string    `json:"failure,omitempty"`
+	OpenedAt   time.Time  `json:"openedAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	// total downtime, or the downtime so far for open incidents
+	DowntimeSeconds int32      `json:"downtimeSeconds"`
+	AcknowledgedAt  *time.Time `json:"acknowledgedAt,omitempty"`
+	// id of the user who acknowledged the incident
+	AcknowledgedBy *string `json:"acknowledgedBy,omitempty"`
+	// number of escalation steps already notified
+	EscalationStep int32                 `json:"escalationStep"`
+	Attempts       []*RemediationAttempt `json:"attempts"`
+}
+
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type LabelInput struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type MaintenanceWindow struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// start of the first occurrence
+	StartsAt        time.Time `json:"startsAt"`
+	DurationMinutes int32     `json:"durationMinutes"`
+	// recurrence rule, e.g. FREQ=WEEKLY;BYDAY=SU, null for one-off windows
+	Rrule    *string `json:"rrule,omitempty"`
+	Timezone string  `json:"timezone"`
+	// monitors covered by the window, together with tags, an empty scope covers every monitor
+	MonitorIds []string   `json:"monitorIds"`
+	Tags       []string   `json:"tags"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	CreatedBy  *string    `json:"createdBy,omitempty"`
+	// whether an occurrence is in progress
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type MaintenanceWindowInput struct {
+	Name            string    `json:"name"`
+	StartsAt        time.Time `json:"startsAt"`
+	DurationMinutes int32     `json:"durationMinutes"`
+	// supports FREQ (HOURLY, DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY, COUNT and UNTIL
+	Rrule *string `json:"rrule,omitempty"`
+	// defaults to UTC
+	Timezone   *string    `json:"timezone,omitempty"`
+	MonitorIds []string   `json:"monitorIds,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+type MonitorStatus struct {
+	MonitorType MonitorType  `json:"monitorType"`
+	MonitorID   string       `json:"monitorId"`
+	MonitorName string       `json:"monitorName"`
+	State       MonitorState `json:"state"`
+	// null for the first check of a monitor
+	PreviousState *MonitorState `json:"previousState,omitempty"`
+	// status as reported by docker or pm2
+	Status      string  `json:"status"`
+	ContainerID *string `json:"containerId,omitempty"`
+	Pid         *int32  `json:"pid,omitempty"`
+	// maintenance window or silence covering the monitor
+	SuppressedBy *string   `json:"suppressedBy,omitempty"`
+	At           time.Time `json:"at"`
+}
+
 type Mutation struct {
 }
 
+type ProcessMonitor struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Command     string    `json:"command"`
+	Pwd         string    `json:"pwd"`
+	Env         []*EnvVar `json:"env"`
+	Interpreter string    `json:"interpreter"`
+	Args        []string  `json:"args"`
+	Enabled     bool      `json:"enabled"`
+	Critical    bool      `json:"critical"`
+	// groups monitors for maintenance windows and silences
+	Tags               []string `json:"tags"`
+	EscalationPolicyID *string  `json:"escalationPolicyId,omitempty"`
+	// failed checks before the monitor is down, null uses the global FAILURE_THRESHOLD
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// passed checks before a down monitor is up, null uses the global RECOVERY_THRESHOLD
+	RecoveryThreshold *int32         `json:"recoveryThreshold,omitempty"`
+	RestartPolicy     *RestartPolicy `json:"restartPolicy"`
+	StartPolicy       StartPolicy    `json:"startPolicy"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+type ProcessState struct {
+	Monitor *ProcessMonitor `json:"monitor"`
+	// pm2 status of the process, or missing when pm2 does not know it
+	Status string `json:"status"`
+	Pid    *int32 `json:"pid,omitempty"`
+	PmID   *int32 `json:"pmId,omitempty"`
+	// seconds since pm2 last started the process
+	Uptime    *int32     `json:"uptime,omitempty"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+type PushSubscription struct {
+	ID        string    `json:"id"`
+	Endpoint  string    `json:"endpoint"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// same shape as the browser PushSubscription.toJSON() without expirationTime
+type PushSubscriptionInput struct {
+	Endpoint string                     `json:"endpoint"`
+	Keys     *PushSubscriptionKeysInput `json:"keys"`
+}
+
+type PushSubscriptionKeysInput struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
 type Query struct {
 }
 
+// credentials for pulling images from a registry, the password is never returned
+type RegistryCredential struct {
+	ID string `json:"id"`
+	// registry host, e.g. ghcr.io or docker.io
+	Registry  string    `json:"registry"`
+	Username  string    `json:"username"`
+	CreatedBy *string   `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type RegistryCredentialInput struct {
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+	// password or access token, encrypted with WATCHDOG_SECRET_KEY before it is stored
+	Password string `json:"password"`
+}
+
+type RemediationAttempt struct {
+	Action  string    `json:"action"`
+	Success bool      `json:"success"`
+	Error   *string   `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+type RemediationLogEntry struct {
+	MonitorType MonitorType        `json:"monitorType"`
+	MonitorID   string             `json:"monitorId"`
+	MonitorName string             `json:"monitorName"`
+	Action      string             `json:"action"`
+	Outcome     RemediationOutcome `json:"outcome"`
+	Error       *string            `json:"error,omitempty"`
+	At          time.Time          `json:"at"`
+}
+
+// null numbers use the global defaults
+type RestartPolicy struct {
+	Mode RestartMode `json:"mode"`
+	// restarts within windowMinutes before the watchdog gives up, -1 retries forever
+	MaxRetries    *int32 `json:"maxRetries,omitempty"`
+	WindowMinutes *int32 `json:"windowMinutes,omitempty"`
+	// backoff between restarts doubles from initialBackoffSeconds up to maxBackoffSeconds
+	InitialBackoffSeconds *int32 `json:"initialBackoffSeconds,omitempty"`
+	MaxBackoffSeconds     *int32 `json:"maxBackoffSeconds,omitempty"`
+}
+
+type RestartPolicyInput struct {
+	Mode                  RestartMode `json:"mode"`
+	MaxRetries            *int32      `json:"maxRetries,omitempty"`
+	WindowMinutes         *int32      `json:"windowMinutes,omitempty"`
+	InitialBackoffSeconds *int32      `json:"initialBackoffSeconds,omitempty"`
+	MaxBackoffSeconds     *int32      `json:"maxBackoffSeconds,omitempty"`
+}
+
+type RestartState struct {
+	MonitorType MonitorType `json:"monitorType"`
+	MonitorID   string      `json:"monitorId"`
+	// restarts within the window of the restart policy
+	Attempts      []*time.Time `json:"attempts"`
+	NextAttemptAt *time.Time   `json:"nextAttemptAt,omitempty"`
+	// set once the watchdog gave up, until the monitor is up again
+	ExhaustedAt *time.Time `json:"exhaustedAt,omitempty"`
+}
+
+type Silence struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	// monitors covered by the silence, together with tags, an empty scope covers every monitor
+	MonitorIds []string  `json:"monitorIds"`
+	Tags       []string  `json:"tags"`
+	StartsAt   time.Time `json:"startsAt"`
+	// null until the silence is deleted
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedBy *string    `json:"createdBy,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type SilenceInput struct {
+	Reason     string   `json:"reason"`
+	MonitorIds []string `json:"monitorIds,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// defaults to now
+	StartsAt  *time.Time `json:"startsAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// fires when the metric stays above the threshold for durationMinutes, e.g. memory percent above 90 for 5 minutes
+type StatRule struct {
+	Metric          StatMetric `json:"metric"`
+	Above           float64    `json:"above"`
+	DurationMinutes int32      `json:"durationMinutes"`
+	// restart the container once the rule fires, within its restart policy
+	Restart bool `json:"restart"`
+}
+
+type StatRuleInput struct {
+	Metric StatMetric `json:"metric"`
+	Above  float64    `json:"above"`
+	// defaults to 0, firing on the first reading above the threshold
+	DurationMinutes *int32 `json:"durationMinutes,omitempty"`
+	Restart         *bool  `json:"restart,omitempty"`
+}
+
+type SubscribeInput struct {
+	MonitorType MonitorType `json:"monitorType"`
+	MonitorID   *string     `json:"monitorId,omitempty"`
+	Channels    []Channel   `json:"channels"`
+	MinSeverity *Severity   `json:"minSeverity,omitempty"`
+	// required for the WEBHOOK channel
+	WebhookURL *string `json:"webhookUrl,omitempty"`
+	// payloads are signed with HMAC-SHA256 when set
+	WebhookSecret *string `json:"webhookSecret,omitempty"`
+	// incoming webhook url, required for the SLACK channel
+	SlackWebhookURL *string `json:"slackWebhookUrl,omitempty"`
+	// incoming webhook url, required for the DISCORD channel
+	DiscordWebhookURL *string `json:"discordWebhookUrl,omitempty"`
+}
+
+type Subscription struct {
+}
+
+type UpdateComposeProjectMonitorInput struct {
+	Name *string `json:"name,omitempty"`
+	// replaces the expected services and forgets the services found so far
+	Services           []*ComposeServiceInput `json:"services,omitempty"`
+	Critical           *bool                  `json:"critical,omitempty"`
+	Tags               []string               `json:"tags,omitempty"`
+	EscalationPolicyID *string                `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32 `json:"recoveryThreshold,omitempty"`
+	// replaces the whole restart policy
+	RestartPolicy *RestartPolicyInput `json:"restartPolicy,omitempty"`
+}
+
+type UpdateContainerMonitorInput struct {
+	Name               *string       `json:"name,omitempty"`
+	Image              *string       `json:"image,omitempty"`
+	Env                []string      `json:"env,omitempty"`
+	Command            []string      `json:"command,omitempty"`
+	Ports              []string      `json:"ports,omitempty"`
+	Mounts             []string      `json:"mounts,omitempty"`
+	Network            *string       `json:"network,omitempty"`
+	Labels             []*LabelInput `json:"labels,omitempty"`
+	Critical           *bool         `json:"critical,omitempty"`
+	Tags               []string      `json:"tags,omitempty"`
+	EscalationPolicyID *string       `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32 `json:"recoveryThreshold,omitempty"`
+	// replaces the whole restart policy
+	RestartPolicy *RestartPolicyInput `json:"restartPolicy,omitempty"`
+	// 0 never restarts unhealthy containers
+	UnhealthyGraceSeconds *int32 `json:"unhealthyGraceSeconds,omitempty"`
+	// replaces the resource rules
+	StatRules []*StatRuleInput `json:"statRules,omitempty"`
+}
+
+type UpdateProcessMonitorInput struct {
+	Name               *string        `json:"name,omitempty"`
+	Command            *string        `json:"command,omitempty"`
+	Pwd                *string        `json:"pwd,omitempty"`
+	Env                []*EnvVarInput `json:"env,omitempty"`
+	Interpreter        *string        `json:"interpreter,omitempty"`
+	Args               []string       `json:"args,omitempty"`
+	StartPolicy        *StartPolicy   `json:"startPolicy,omitempty"`
+	Critical           *bool          `json:"critical,omitempty"`
+	Tags               []string       `json:"tags,omitempty"`
+	EscalationPolicyID *string        `json:"escalationPolicyId,omitempty"`
+	// 0 uses the global threshold
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// 0 uses the global threshold
+	RecoveryThreshold *int32 `json:"recoveryThreshold,omitempty"`
+	// replaces the whole restart policy
+	RestartPolicy *RestartPolicyInput `json:"restartPolicy,omitempty"`
+}
+
 type User struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	Phone         *string `json:"phone,omitempty"`
+	PhoneVerified bool    `json:"phoneVerified"`
+}
+
+type WebhookInput struct {
+	URL    string  `json:"url"`
+	Secret *string `json:"secret,omitempty"`
+}
+
+type Channel string
+
+const (
+	ChannelEmail   Channel = "EMAIL"
+	ChannelWebhook Channel = "WEBHOOK"
+	ChannelSLACk   Channel = "SLACK"
+	ChannelDiscord Channel = "DISCORD"
+	// texts the verified phone number of the user when a monitor goes down
+	ChannelSms Channel = "SMS"
+	// calls the verified phone number of the user when a critical monitor goes down
+	ChannelCall Channel = "CALL"
+	// browser push to every browser the user registered
+	ChannelPush Channel = "PUSH"
+)
+
+var AllChannel = []Channel{
+	ChannelEmail,
+	ChannelWebhook,
+	ChannelSLACk,
+	ChannelDiscord,
+	ChannelSms,
+	ChannelCall,
+	ChannelPush,
+}
+
+func (e Channel) IsValid() bool {
+	switch e {
+	case ChannelEmail, ChannelWebhook, ChannelSLACk, ChannelDiscord, ChannelSms, ChannelCall, ChannelPush:
+		return true
+	}
+	return false
+}
+
+func (e Channel) String() string {
+	return string(e)
+}
+
+func (e *Channel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Channel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Channel", str)
+	}
+	return nil
+}
+
+func (e Channel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen     IncidentStatus = "OPEN"
+	IncidentStatusResolved IncidentStatus = "RESOLVED"
+)
+
+var AllIncidentStatus = []IncidentStatus{
+	IncidentStatusOpen,
+	IncidentStatusResolved,
+}
+
+func (e IncidentStatus) IsValid() bool {
+	switch e {
+	case IncidentStatusOpen, IncidentStatusResolved:
+		return true
+	}
+	return false
+}
+
+func (e IncidentStatus) String() string {
+	return string(e)
+}
+
+func (e *IncidentStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = IncidentStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid IncidentStatus", str)
+	}
+	return nil
+}
+
+func (e IncidentStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type MonitorState string
+
+const (
+	MonitorStateUp       MonitorState = "UP"
+	MonitorStateSuspect  MonitorState = "SUSPECT"
+	MonitorStateDown     MonitorState = "DOWN"
+	MonitorStateFlapping MonitorState = "FLAPPING"
+)
+
+var AllMonitorState = []MonitorState{
+	MonitorStateUp,
+	MonitorStateSuspect,
+	MonitorStateDown,
+	MonitorStateFlapping,
+}
+
+func (e MonitorState) IsValid() bool {
+	switch e {
+	case MonitorStateUp, MonitorStateSuspect, MonitorStateDown, MonitorStateFlapping:
+		return true
+	}
+	return false
+}
+
+func (e MonitorState) String() string {
+	return string(e)
+}
+
+func (e *MonitorState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MonitorState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MonitorState", str)
+	}
+	return nil
+}
+
+func (e MonitorState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type MonitorType string
+
+const (
+	MonitorTypeContainer MonitorType = "CONTAINER"
+	MonitorTypeProcess   MonitorType = "PROCESS"
+	MonitorTypeCompose   MonitorType = "COMPOSE"
+	MonitorTypeAll       MonitorType = "ALL"
+)
+
+var AllMonitorType = []MonitorType{
+	MonitorTypeContainer,
+	MonitorTypeProcess,
+	MonitorTypeCompose,
+	MonitorTypeAll,
+}
+
+func (e MonitorType) IsValid() bool {
+	switch e {
+	case MonitorTypeContainer, MonitorTypeProcess, MonitorTypeCompose, MonitorTypeAll:
+		return true
+	}
+	return false
+}
+
+func (e MonitorType) String() string {
+	return string(e)
+}
+
+func (e *MonitorType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MonitorType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MonitorType", str)
+	}
+	return nil
+}
+
+func (e MonitorType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RemediationOutcome string
+
+const (
+	RemediationOutcomeStarted   RemediationOutcome = "STARTED"
+	RemediationOutcomeFailed    RemediationOutcome = "FAILED"
+	RemediationOutcomeSucceeded RemediationOutcome = "SUCCEEDED"
+	// the restart policy gave up on the monitor
+	RemediationOutcomeExhausted RemediationOutcome = "EXHAUSTED"
+)
+
+var AllRemediationOutcome = []RemediationOutcome{
+	RemediationOutcomeStarted,
+	RemediationOutcomeFailed,
+	RemediationOutcomeSucceeded,
+	RemediationOutcomeExhausted,
+}
+
+func (e RemediationOutcome) IsValid() bool {
+	switch e {
+	case RemediationOutcomeStarted, RemediationOutcomeFailed, RemediationOutcomeSucceeded, RemediationOutcomeExhausted:
+		return true
+	}
+	return false
+}
+
+func (e RemediationOutcome) String() string {
+	return string(e)
+}
+
+func (e *RemediationOutcome) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RemediationOutcome(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RemediationOutcome", str)
+	}
+	return nil
+}
+
+func (e RemediationOutcome) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RestartMode string
+
+const (
+	RestartModeAlways RestartMode = "ALWAYS"
+	// only restart monitors that did not exit cleanly
+	RestartModeOnFailure RestartMode = "ON_FAILURE"
+	RestartModeNever     RestartMode = "NEVER"
+)
+
+var AllRestartMode = []RestartMode{
+	RestartModeAlways,
+	RestartModeOnFailure,
+	RestartModeNever,
+}
+
+func (e RestartMode) IsValid() bool {
+	switch e {
+	case RestartModeAlways, RestartModeOnFailure, RestartModeNever:
+		return true
+	}
+	return false
+}
+
+func (e RestartMode) String() string {
+	return string(e)
+}
+
+func (e *RestartMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RestartMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RestartMode", str)
+	}
+	return nil
+}
+
+func (e RestartMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "INFO"
+	SeverityWarning  Severity = "WARNING"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+var AllSeverity = []Severity{
+	SeverityInfo,
+	SeverityWarning,
+	SeverityCritical,
+}
+
+func (e Severity) IsValid() bool {
+	switch e {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+func (e Severity) String() string {
+	return string(e)
+}
+
+func (e *Severity) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Severity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Severity", str)
+	}
+	return nil
+}
+
+func (e Severity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StartPolicy string
+
+const (
+	StartPolicyAuto   StartPolicy = "AUTO"
+	StartPolicyManual StartPolicy = "MANUAL"
+)
+
+var AllStartPolicy = []StartPolicy{
+	StartPolicyAuto,
+	StartPolicyManual,
+}
+
+func (e StartPolicy) IsValid() bool {
+	switch e {
+	case StartPolicyAuto, StartPolicyManual:
+		return true
+	}
+	return false
+}
+
+func (e StartPolicy) String() string {
+	return string(e)
+}
+
+func (e *StartPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StartPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StartPolicy", str)
+	}
+	return nil
+}
+
+func (e StartPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StatMetric string
+
+const (
+	StatMetricCPUPercent    StatMetric = "CPU_PERCENT"
+	StatMetricMemoryPercent StatMetric = "MEMORY_PERCENT"
+	StatMetricMemoryBytes   StatMetric = "MEMORY_BYTES"
+)
+
+var AllStatMetric = []StatMetric{
+	StatMetricCPUPercent,
+	StatMetricMemoryPercent,
+	StatMetricMemoryBytes,
+}
+
+func (e StatMetric) IsValid() bool {
+	switch e {
+	case StatMetricCPUPercent, StatMetricMemoryPercent, StatMetricMemoryBytes:
+		return true
+	}
+	return false
+}
+
+func (e StatMetric) String() string {
+	return string(e)
+}
+
+func (e *StatMetric) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatMetric(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatMetric", str)
+	}
+	return nil
+}
+
+func (e StatMetric) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
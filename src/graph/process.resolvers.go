@@ -0,0 +1,253 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/process"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateProcessMonitor is the resolver for the createProcessMonitor field.
+func (r *mutationResolver) CreateProcessMonitor(ctx context.Context, input model.CreateProcessMonitorInput) (*model.ProcessMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	monitor := db.DbPm2Process{
+		ID:          uuid.New().String(),
+		Name:        input.Name,
+		Command:     input.Command,
+		Env:         envFromInput(input.Env),
+		Args:        input.Args,
+		Tags:        input.Tags,
+		Enabled:     true,
+		StartPolicy: db.StartPolicyAuto,
+	}
+	if input.Pwd != nil {
+		monitor.PWD = *input.Pwd
+	}
+	if input.Interpreter != nil {
+		monitor.Interpreter = *input.Interpreter
+	}
+	if input.Enabled != nil {
+		monitor.Enabled = *input.Enabled
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+	if input.StartPolicy != nil {
+		monitor.StartPolicy = fromStartPolicy(*input.StartPolicy)
+	}
+
+	if err := validateProcessMonitor(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Create(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toProcessMonitor(monitor, true), nil
+}
+
+// UpdateProcessMonitor is the resolver for the updateProcessMonitor field.
+func (r *mutationResolver) UpdateProcessMonitor(ctx context.Context, id string, input model.UpdateProcessMonitorInput) (*model.ProcessMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.DbPm2Process
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("process monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	if input.Name != nil {
+		monitor.Name = *input.Name
+	}
+	if input.Command != nil {
+		monitor.Command = *input.Command
+	}
+	if input.Pwd != nil {
+		monitor.PWD = *input.Pwd
+	}
+	if input.Env != nil {
+		monitor.Env = envFromInput(input.Env)
+	}
+	if input.Interpreter != nil {
+		monitor.Interpreter = *input.Interpreter
+	}
+	if input.Args != nil {
+		monitor.Args = input.Args
+	}
+	if input.StartPolicy != nil {
+		monitor.StartPolicy = fromStartPolicy(*input.StartPolicy)
+	}
+
+	if input.Tags != nil {
+		monitor.Tags = input.Tags
+	}
+	if input.Critical != nil {
+		monitor.Critical = *input.Critical
+	}
+	if input.EscalationPolicyID != nil {
+		policyID, err := escalationPolicyID(*input.EscalationPolicyID)
+		if err != nil {
+			return nil, err
+		}
+		monitor.EscalationPolicyID = policyID
+	}
+	if input.FailureThreshold != nil {
+		failures, err := threshold("failureThreshold", *input.FailureThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.FailureThreshold = failures
+	}
+	if input.RecoveryThreshold != nil {
+		successes, err := threshold("recoveryThreshold", *input.RecoveryThreshold)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RecoveryThreshold = successes
+	}
+	if input.RestartPolicy != nil {
+		policy, err := restartPolicyFromInput(*input.RestartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		monitor.RestartPolicy = policy
+	}
+
+	if err := validateProcessMonitor(monitor); err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toProcessMonitor(monitor, true), nil
+}
+
+// SetProcessMonitorEnabled is the resolver for the setProcessMonitorEnabled field.
+func (r *mutationResolver) SetProcessMonitorEnabled(ctx context.Context, id string, enabled bool) (*model.ProcessMonitor, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var monitor db.DbPm2Process
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("process monitor %s not found", id)
+		}
+		return nil, err
+	}
+
+	monitor.Enabled = enabled
+	if err := db.DB.Save(&monitor).Error; err != nil {
+		return nil, err
+	}
+
+	return toProcessMonitor(monitor, true), nil
+}
+
+// DeleteProcessMonitor is the resolver for the deleteProcessMonitor field.
+func (r *mutationResolver) DeleteProcessMonitor(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.DbPm2Process{}, "id = ?", id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ProcessMonitors is the resolver for the processMonitors field.
+func (r *queryResolver) ProcessMonitors(ctx context.Context) ([]*model.ProcessMonitor, error) {
+	var monitors []db.DbPm2Process
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.ProcessMonitor{}
+	for _, monitor := range monitors {
+		list = append(list, toProcessMonitor(monitor, false))
+	}
+
+	return list, nil
+}
+
+// ProcessMonitor is the resolver for the processMonitor field.
+func (r *queryResolver) ProcessMonitor(ctx context.Context, id string) (*model.ProcessMonitor, error) {
+	var monitor db.DbPm2Process
+	if err := db.DB.First(&monitor, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// env values may hold secrets
+	_, err := currentUser(ctx)
+
+	return toProcessMonitor(monitor, err == nil), nil
+}
+
+// Processes is the resolver for the processes field.
+func (r *queryResolver) Processes(ctx context.Context) ([]*model.ProcessState, error) {
+	var monitors []db.DbPm2Process
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		return nil, err
+	}
+
+	live := process.GetPm2Processes(r.Logger)
+
+	list := []*model.ProcessState{}
+	for _, monitor := range monitors {
+		list = append(list, toProcessState(monitor, live))
+	}
+
+	return list, nil
+}
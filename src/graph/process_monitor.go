@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/process"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateProcessMonitor(monitor db.DbPm2Process) error {
+	if strings.TrimSpace(monitor.Name) == "" {
+		return fmt.Errorf("process name is required")
+	}
+	// names end up in alert subjects and headers
+	if strings.ContainsFunc(monitor.Name, unicode.IsControl) {
+		return fmt.Errorf("invalid process name %q: control characters are not allowed", monitor.Name)
+	}
+
+	if strings.TrimSpace(monitor.Command) == "" {
+		return fmt.Errorf("process command is required")
+	}
+
+	if monitor.PWD != "" && !filepath.IsAbs(monitor.PWD) {
+		return fmt.Errorf("invalid pwd %q: must be an absolute path", monitor.PWD)
+	}
+
+	for _, vars := range monitor.Env {
+		for key := range vars {
+			if !envKeyPattern.MatchString(key) {
+				return fmt.Errorf("invalid env name %q", key)
+			}
+		}
+	}
+
+	if err := validateTags(monitor.Tags); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.DB.Model(&db.DbPm2Process{}).Where("name = ? AND id <> ?", monitor.Name, monitor.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a process monitor named %q already exists", monitor.Name)
+	}
+
+	return nil
+}
+
+func envFromInput(input []*model.EnvVarInput) []map[string]string {
+	env := map[string]string{}
+	for _, v := range input {
+		env[v.Key] = v.Value
+	}
+
+	return []map[string]string{env}
+}
+
+// toEnvVars lists the env of a process, values are left out unless withValues
+// is set since they may hold secrets.
+func toEnvVars(env []map[string]string, withValues bool) []*model.EnvVar {
+	list := []*model.EnvVar{}
+	for _, vars := range env {
+		for key, value := range vars {
+			v := &model.EnvVar{Key: key}
+			if withValues {
+				v.Value = &value
+			}
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+
+	return list
+}
+
+func toStartPolicy(policy string) model.StartPolicy {
+	if policy == db.StartPolicyManual {
+		return model.StartPolicyManual
+	}
+
+	return model.StartPolicyAuto
+}
+
+func fromStartPolicy(policy model.StartPolicy) string {
+	return strings.ToLower(policy.String())
+}
+
+func toProcessMonitor(monitor db.DbPm2Process, withEnv bool) *model.ProcessMonitor {
+	return &model.ProcessMonitor{
+		ID:                 monitor.ID,
+		Name:               monitor.Name,
+		Command:            monitor.Command,
+		Pwd:                monitor.PWD,
+		Env:                toEnvVars(monitor.Env, withEnv),
+		Interpreter:        monitor.Interpreter,
+		Args:               emptyIfNil(monitor.Args),
+		Enabled:            monitor.Enabled,
+		Critical:           monitor.Critical,
+		Tags:               emptyIfNil(monitor.Tags),
+		EscalationPolicyID: monitor.EscalationPolicyID,
+		FailureThreshold:   thresholdOrNil(monitor.FailureThreshold),
+		RecoveryThreshold:  thresholdOrNil(monitor.RecoveryThreshold),
+		RestartPolicy:      toRestartPolicy(monitor.RestartPolicy),
+		StartPolicy:        toStartPolicy(monitor.StartPolicy),
+		CreatedAt:          monitor.CreatedAt,
+		UpdatedAt:          monitor.UpdatedAt,
+	}
+}
+
+func toProcessState(monitor db.DbPm2Process, live []process.Pm2Process) *model.ProcessState {
+	state := &model.ProcessState{
+		Monitor: toProcessMonitor(monitor, false),
+		Status:  "missing",
+	}
+
+	for _, p := range live {
+		if p.Name != monitor.Name {
+			continue
+		}
+
+		pid := int32(p.PID)
+		pmID := int32(p.PmID)
+		state.Status = p.Status
+		state.Pid = &pid
+		state.PmID = &pmID
+
+		if p.Status == "online" && p.PmUptime > 0 {
+			startedAt := time.UnixMilli(p.PmUptime)
+			uptime := int32(time.Since(startedAt).Seconds())
+			state.StartedAt = &startedAt
+			state.Uptime = &uptime
+		}
+		break
+	}
+
+	return state
+}
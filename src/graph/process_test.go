@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+func TestProcessMonitorMutationsNeedAUser(t *testing.T) {
+	dbtest.Use(t)
+	resolver := &Resolver{}
+	ctx := context.Background()
+
+	if _, err := resolver.Mutation().CreateProcessMonitor(ctx, model.CreateProcessMonitorInput{Name: "shell", Command: "rm -rf /"}); err == nil {
+		t.Fatal("created a process monitor")
+	}
+	if _, err := resolver.Mutation().UpdateProcessMonitor(ctx, "api", model.UpdateProcessMonitorInput{}); err == nil {
+		t.Fatal("updated a process monitor")
+	}
+	if _, err := resolver.Mutation().SetProcessMonitorEnabled(ctx, "api", false); err == nil {
+		t.Fatal("disabled a process monitor")
+	}
+	if _, err := resolver.Mutation().DeleteProcessMonitor(ctx, "api"); err == nil {
+		t.Fatal("deleted a process monitor")
+	}
+
+	var count int64
+	db.DB.Model(&db.DbPm2Process{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d process monitors stored", count)
+	}
+}
+
+func TestProcessMonitorEnv(t *testing.T) {
+	dbtest.Use(t)
+	resolver := &Resolver{}
+	ctx := signedIn(t)
+
+	created, err := resolver.Mutation().CreateProcessMonitor(ctx, model.CreateProcessMonitorInput{
+		Name:    "api",
+		Command: "server.js",
+		Env:     []*model.EnvVarInput{{Key: "TOKEN", Value: "secret"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withValue := func(env []*model.EnvVar) bool {
+		return len(env) == 1 && env[0].Key == "TOKEN" && env[0].Value != nil && *env[0].Value == "secret"
+	}
+	keyOnly := func(env []*model.EnvVar) bool {
+		return len(env) == 1 && env[0].Key == "TOKEN" && env[0].Value == nil
+	}
+
+	if !withValue(created.Env) {
+		t.Fatalf("created monitor env = %+v", created.Env)
+	}
+
+	list, err := resolver.Query().ProcessMonitors(ctx)
+	if err != nil || len(list) != 1 || !keyOnly(list[0].Env) {
+		t.Fatalf("ProcessMonitors() = %+v, %v, want only the keys", list, err)
+	}
+
+	single, err := resolver.Query().ProcessMonitor(ctx, created.ID)
+	if err != nil || !withValue(single.Env) {
+		t.Fatalf("signed in ProcessMonitor() = %+v, %v", single, err)
+	}
+
+	anonymous, err := resolver.Query().ProcessMonitor(context.Background(), created.ID)
+	if err != nil || !keyOnly(anonymous.Env) {
+		t.Fatalf("anonymous ProcessMonitor() = %+v, %v", anonymous, err)
+	}
+}
@@ -0,0 +1,91 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/google/uuid"
+)
+
+// RegisterPushSubscription is the resolver for the registerPushSubscription field.
+func (r *mutationResolver) RegisterPushSubscription(ctx context.Context, input model.PushSubscriptionInput) (*model.PushSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePushSubscription(input); err != nil {
+		return nil, err
+	}
+
+	// A browser keeps its endpoint when it re-subscribes, possibly for another user
+	var subscription db.PushSubscription
+	if err := db.DB.Where("endpoint = ?", input.Endpoint).Limit(1).Find(&subscription).Error; err != nil {
+		return nil, err
+	}
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+
+	subscription.UserID = user.ID
+	subscription.Endpoint = input.Endpoint
+	subscription.P256dh = input.Keys.P256dh
+	subscription.Auth = input.Keys.Auth
+
+	if err := db.DB.Omit("User").Save(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return toPushSubscription(subscription), nil
+}
+
+// UnregisterPushSubscription is the resolver for the unregisterPushSubscription field.
+func (r *mutationResolver) UnregisterPushSubscription(ctx context.Context, endpoint string) (bool, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.PushSubscription{}, "endpoint = ? AND user_id = ?", endpoint, user.ID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// VapidPublicKey is the resolver for the vapidPublicKey field.
+func (r *queryResolver) VapidPublicKey(ctx context.Context) (*string, error) {
+	key := notifier.VAPIDPublicKey()
+	if key == "" {
+		return nil, nil
+	}
+
+	return &key, nil
+}
+
+// MyPushSubscriptions is the resolver for the myPushSubscriptions field.
+func (r *queryResolver) MyPushSubscriptions(ctx context.Context) ([]*model.PushSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []db.PushSubscription
+	if err := db.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.PushSubscription{}
+	for _, subscription := range subscriptions {
+		list = append(list, toPushSubscription(subscription))
+	}
+
+	return list, nil
+}
@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+func validatePushSubscription(input model.PushSubscriptionInput) error {
+	if err := validateWebhookURL(input.Endpoint); err != nil {
+		return fmt.Errorf("invalid push endpoint %q", input.Endpoint)
+	}
+
+	p256dh, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(input.Keys.P256dh, "="))
+	if err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	auth, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(input.Keys.Auth, "="))
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("invalid auth secret: expected 16 bytes")
+	}
+
+	return nil
+}
+
+func toPushSubscription(subscription db.PushSubscription) *model.PushSubscription {
+	return &model.PushSubscription{
+		ID:        subscription.ID,
+		Endpoint:  subscription.Endpoint,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
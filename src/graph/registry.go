@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+)
+
+// registryCredentialFromInput validates the input and encrypts its password.
+func registryCredentialFromInput(credential *db.RegistryCredential, input model.RegistryCredentialInput) error {
+	registry := docker.NormalizeRegistry(input.Registry)
+	if registry == "" {
+		return fmt.Errorf("registry is required")
+	}
+	if input.Username == "" || input.Password == "" {
+		return fmt.Errorf("username and password are required")
+	}
+
+	password, err := secrets.Encrypt(input.Password)
+	if err != nil {
+		return err
+	}
+
+	credential.Registry = registry
+	credential.Username = input.Username
+	credential.PasswordEncrypted = password
+
+	return nil
+}
+
+func toRegistryCredential(credential db.RegistryCredential) *model.RegistryCredential {
+	result := &model.RegistryCredential{
+		ID:        credential.ID,
+		Registry:  credential.Registry,
+		Username:  credential.Username,
+		CreatedAt: credential.CreatedAt,
+		UpdatedAt: credential.UpdatedAt,
+	}
+	if credential.CreatedBy != "" {
+		result.CreatedBy = &credential.CreatedBy
+	}
+
+	return result
+}
@@ -0,0 +1,74 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SetRegistryCredential is the resolver for the setRegistryCredential field.
+func (r *mutationResolver) SetRegistryCredential(ctx context.Context, input model.RegistryCredentialInput) (*model.RegistryCredential, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var credential db.RegistryCredential
+	err = db.DB.First(&credential, "registry = ?", docker.NormalizeRegistry(input.Registry)).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		credential = db.RegistryCredential{ID: uuid.New().String()}
+	}
+
+	if err := registryCredentialFromInput(&credential, input); err != nil {
+		return nil, err
+	}
+	credential.CreatedBy = user.ID
+
+	if err := db.DB.Save(&credential).Error; err != nil {
+		return nil, err
+	}
+
+	return toRegistryCredential(credential), nil
+}
+
+// DeleteRegistryCredential is the resolver for the deleteRegistryCredential field.
+func (r *mutationResolver) DeleteRegistryCredential(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.RegistryCredential{}, "id = ?", id)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// RegistryCredentials is the resolver for the registryCredentials field.
+func (r *queryResolver) RegistryCredentials(ctx context.Context) ([]*model.RegistryCredential, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return nil, err
+	}
+
+	var credentials []db.RegistryCredential
+	if err := db.DB.Order("registry").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+
+	result := []*model.RegistryCredential{}
+	for _, credential := range credentials {
+		result = append(result, toRegistryCredential(credential))
+	}
+
+	return result, nil
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/rs/zerolog"
 )
 
 // This file will not be regenerated automatically.
@@ -10,4 +11,5 @@ import (
 
 type Resolver struct {
 	UserStore map[string]model.User
+	Logger    zerolog.Logger
 }
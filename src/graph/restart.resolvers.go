@@ -0,0 +1,42 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/restart"
+	"gorm.io/gorm"
+)
+
+// ResetRestartState is the resolver for the resetRestartState field.
+func (r *mutationResolver) ResetRestartState(ctx context.Context, monitorType model.MonitorType, monitorID string) (bool, error) {
+	if _, err := currentUser(ctx); err != nil {
+		return false, err
+	}
+
+	if err := restart.Clear(strings.ToLower(monitorType.String()), monitorID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RestartState is the resolver for the restartState field.
+func (r *queryResolver) RestartState(ctx context.Context, monitorType model.MonitorType, monitorID string) (*model.RestartState, error) {
+	var state db.RestartState
+	err := db.DB.First(&state, "monitor_type = ? AND monitor_id = ?", strings.ToLower(monitorType.String()), monitorID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return toRestartState(state), nil
+}
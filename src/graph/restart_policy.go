@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+func restartPolicyFromInput(input model.RestartPolicyInput) (db.RestartPolicy, error) {
+	policy := db.RestartPolicy{
+		Mode: strings.ReplaceAll(strings.ToLower(input.Mode.String()), "_", "-"),
+	}
+
+	if input.MaxRetries != nil {
+		if *input.MaxRetries < -1 {
+			return policy, fmt.Errorf("maxRetries must be -1 or more")
+		}
+		policy.MaxRetries = int(*input.MaxRetries)
+	}
+
+	for name, value := range map[string]*int32{
+		"windowMinutes":         input.WindowMinutes,
+		"initialBackoffSeconds": input.InitialBackoffSeconds,
+		"maxBackoffSeconds":     input.MaxBackoffSeconds,
+	} {
+		if value != nil && *value < 0 {
+			return policy, fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if input.WindowMinutes != nil {
+		policy.WindowMinutes = int(*input.WindowMinutes)
+	}
+	if input.InitialBackoffSeconds != nil {
+		policy.InitialBackoffSeconds = int(*input.InitialBackoffSeconds)
+	}
+	if input.MaxBackoffSeconds != nil {
+		policy.MaxBackoffSeconds = int(*input.MaxBackoffSeconds)
+	}
+
+	if policy.InitialBackoffSeconds > 0 && policy.MaxBackoffSeconds > 0 && policy.InitialBackoffSeconds > policy.MaxBackoffSeconds {
+		return policy, fmt.Errorf("initialBackoffSeconds must not exceed maxBackoffSeconds")
+	}
+
+	return policy, nil
+}
+
+func toRestartPolicy(policy db.RestartPolicy) *model.RestartPolicy {
+	mode := policy.Mode
+	if mode == "" {
+		mode = db.RestartAlways
+	}
+
+	return &model.RestartPolicy{
+		Mode:                  model.RestartMode(strings.ToUpper(strings.ReplaceAll(mode, "-", "_"))),
+		MaxRetries:            thresholdOrNil(policy.MaxRetries),
+		WindowMinutes:         thresholdOrNil(policy.WindowMinutes),
+		InitialBackoffSeconds: thresholdOrNil(policy.InitialBackoffSeconds),
+		MaxBackoffSeconds:     thresholdOrNil(policy.MaxBackoffSeconds),
+	}
+}
+
+func toRestartState(state db.RestartState) *model.RestartState {
+	attempts := []*time.Time{}
+	for i := range state.Attempts {
+		attempts = append(attempts, &state.Attempts[i])
+	}
+
+	return &model.RestartState{
+		MonitorType:   model.MonitorType(strings.ToUpper(state.MonitorType)),
+		MonitorID:     state.MonitorID,
+		Attempts:      attempts,
+		NextAttemptAt: state.NextAttemptAt,
+		ExhaustedAt:   state.ExhaustedAt,
+	}
+}
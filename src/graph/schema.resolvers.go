@@ -6,9 +6,13 @@ package graph
 
 import (
 	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
 
 	"github.com/PayCryps/WatchdogGo/src/db"
 	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -31,11 +35,93 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUse
 		return nil, err
 	}
 
-	return &model.User{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return toUser(*user), nil
+}
+
+// SetPhoneNumber is the resolver for the setPhoneNumber field.
+func (r *mutationResolver) SetPhoneNumber(ctx context.Context, phone string) (*model.User, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePhone(phone); err != nil {
+		return nil, err
+	}
+
+	provider := notifier.Telephony()
+	if provider == nil {
+		return nil, fmt.Errorf("telephony is not configured")
+	}
+
+	code, hash, err := newPhoneCode()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(phoneCodeTTL)
+	user.Phone = phone
+	user.PhoneVerifiedAt = nil
+	user.PhoneCodeHash = hash
+	user.PhoneCodeExpiresAt = &expiresAt
+	user.PhoneCodeAttempts = 0
+
+	if err := db.DB.Save(user).Error; err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your watchdog verification code is %s", code)
+	if err := provider.SendSMS(ctx, phone, message); err != nil {
+		return nil, fmt.Errorf("failed to send verification code: %w", err)
+	}
+
+	return toUser(*user), nil
+}
+
+// VerifyPhoneNumber is the resolver for the verifyPhoneNumber field.
+func (r *mutationResolver) VerifyPhoneNumber(ctx context.Context, code string) (*model.User, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PhoneCodeHash == "" || user.PhoneCodeExpiresAt == nil || time.Now().After(*user.PhoneCodeExpiresAt) {
+		return nil, fmt.Errorf("no pending verification, request a new code")
+	}
+
+	// count the attempt before comparing so concurrent guesses cannot get past the limit
+	result := db.DB.Model(&db.User{}).Where("id = ? AND phone_code_attempts < ?", user.ID, maxPhoneCodeAttempts).
+		UpdateColumn("phone_code_attempts", gorm.Expr("phone_code_attempts + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("too many wrong codes, request a new code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashPhoneCode(code)), []byte(user.PhoneCodeHash)) != 1 {
+		if user.PhoneCodeAttempts+1 >= maxPhoneCodeAttempts {
+			err := db.DB.Model(&db.User{}).Where("id = ?", user.ID).
+				Updates(map[string]any{"phone_code_hash": "", "phone_code_expires_at": nil}).Error
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("too many wrong codes, request a new code")
+		}
+		return nil, fmt.Errorf("invalid verification code")
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	user.PhoneCodeHash = ""
+	user.PhoneCodeExpiresAt = nil
+	user.PhoneCodeAttempts = 0
+
+	if err := db.DB.Save(user).Error; err != nil {
+		return nil, err
+	}
+
+	return toUser(*user), nil
 }
 
 // GetUser is the resolver for the getUser field.
@@ -49,11 +135,13 @@ func (r *queryResolver) GetUser(ctx context.Context, id string) (*model.User, er
 		return nil, err
 	}
 
-	return &model.User{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	// phone numbers are only shown to their owner
+	result := toUser(user)
+	if caller, err := currentUser(ctx); err != nil || caller.ID != user.ID {
+		result.Phone = nil
+	}
+
+	return result, nil
 }
 
 // Mutation returns MutationResolver implementation.
@@ -64,18 +152,3 @@ func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
-
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *mutationResolver) CreateTodo(ctx context.Context, input model.NewTodo) (*model.Todo, error) {
-	panic(fmt.Errorf("not implemented: CreateTodo - createTodo"))
-}
-func (r *queryResolver) Todos(ctx context.Context) ([]*model.Todo, error) {
-	panic(fmt.Errorf("not implemented: Todos - todos"))
-}
-*/
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+func statRulesFromInput(input []*model.StatRuleInput) ([]db.StatRule, error) {
+	rules := []db.StatRule{}
+	for _, rule := range input {
+		if rule.Above < 0 {
+			return nil, fmt.Errorf("%s threshold must not be negative", strings.ToLower(rule.Metric.String()))
+		}
+
+		converted := db.StatRule{Metric: strings.ToLower(rule.Metric.String()), Above: rule.Above}
+		if rule.DurationMinutes != nil {
+			duration, err := threshold("durationMinutes", *rule.DurationMinutes)
+			if err != nil {
+				return nil, err
+			}
+			converted.DurationMinutes = duration
+		}
+		if rule.Restart != nil {
+			converted.Restart = *rule.Restart
+		}
+		rules = append(rules, converted)
+	}
+
+	return rules, nil
+}
+
+func toStatRules(rules []db.StatRule) []*model.StatRule {
+	list := []*model.StatRule{}
+	for _, rule := range rules {
+		list = append(list, &model.StatRule{
+			Metric:          model.StatMetric(strings.ToUpper(rule.Metric)),
+			Above:           rule.Above,
+			DurationMinutes: int32(rule.DurationMinutes),
+			Restart:         rule.Restart,
+		})
+	}
+
+	return list
+}
+
+func toContainerStatsSample(sample db.ContainerStats) *model.ContainerStatsSample {
+	return &model.ContainerStatsSample{
+		At:            sample.At,
+		CPUPercent:    sample.CPUPercent,
+		MemoryUsage:   float64(sample.MemoryUsage),
+		MemoryLimit:   float64(sample.MemoryLimit),
+		MemoryPercent: sample.MemoryPercent,
+		NetworkRx:     float64(sample.NetworkRx),
+		NetworkTx:     float64(sample.NetworkTx),
+		BlockRead:     float64(sample.BlockRead),
+		BlockWrite:    float64(sample.BlockWrite),
+	}
+}
@@ -0,0 +1,38 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+// ContainerStats is the resolver for the containerStats field.
+func (r *queryResolver) ContainerStats(ctx context.Context, monitorID string, from *time.Time, to *time.Time) ([]*model.ContainerStatsSample, error) {
+	since := time.Now().Add(-time.Hour)
+	if from != nil {
+		since = *from
+	}
+
+	query := db.DB.Where("monitor_id = ? AND at >= ?", monitorID, since)
+	if to != nil {
+		query = query.Where("at <= ?", *to)
+	}
+
+	var samples []db.ContainerStats
+	if err := query.Order("at").Find(&samples).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.ContainerStatsSample{}
+	for _, sample := range samples {
+		list = append(list, toContainerStatsSample(sample))
+	}
+
+	return list, nil
+}
@@ -0,0 +1,143 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.63
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Subscribe is the resolver for the subscribe field.
+func (r *mutationResolver) Subscribe(ctx context.Context, input model.SubscribeInput) (*model.AlertSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := subscriptionFromInput(user, input)
+	if err != nil {
+		return nil, err
+	}
+	subscription.ID = uuid.New().String()
+
+	if err := db.DB.Omit("User").Create(subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return toAlertSubscription(*subscription), nil
+}
+
+// Unsubscribe is the resolver for the unsubscribe field.
+func (r *mutationResolver) Unsubscribe(ctx context.Context, id string) (bool, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.DB.Delete(&db.Subscription{}, "id = ? AND user_id = ?", id, user.ID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// SetSubscriptionWebhook is the resolver for the setSubscriptionWebhook field.
+func (r *mutationResolver) SetSubscriptionWebhook(ctx context.Context, id string, input model.WebhookInput) (*model.AlertSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription db.Subscription
+	if err := db.DB.Preload("User").First(&subscription, "id = ? AND user_id = ?", id, user.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("subscription %s not found", id)
+		}
+		return nil, err
+	}
+
+	if err := validateWebhookURL(input.URL); err != nil {
+		return nil, err
+	}
+
+	subscription.WebhookURL = input.URL
+	subscription.WebhookSecretEncrypted = ""
+	if input.Secret != nil {
+		secret, err := encryptWebhookSecret(*input.Secret)
+		if err != nil {
+			return nil, err
+		}
+		subscription.WebhookSecretEncrypted = secret
+	}
+
+	if err := db.DB.Omit("User").Save(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return toAlertSubscription(subscription), nil
+}
+
+// SetSubscriptionChatWebhook is the resolver for the setSubscriptionChatWebhook field.
+func (r *mutationResolver) SetSubscriptionChatWebhook(ctx context.Context, id string, channel model.Channel, url string) (*model.AlertSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription db.Subscription
+	if err := db.DB.Preload("User").First(&subscription, "id = ? AND user_id = ?", id, user.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("subscription %s not found", id)
+		}
+		return nil, err
+	}
+
+	if err := validateWebhookURL(url); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(channel.String()) {
+	case notifier.ChannelSlack:
+		subscription.SlackWebhookURL = url
+	case notifier.ChannelDiscord:
+		subscription.DiscordWebhookURL = url
+	default:
+		return nil, fmt.Errorf("%s is not a chat channel", channel)
+	}
+
+	if err := db.DB.Omit("User").Save(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	return toAlertSubscription(subscription), nil
+}
+
+// MySubscriptions is the resolver for the mySubscriptions field.
+func (r *queryResolver) MySubscriptions(ctx context.Context) ([]*model.AlertSubscription, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []db.Subscription
+	if err := db.DB.Preload("User").Where("user_id = ?", user.ID).Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	list := []*model.AlertSubscription{}
+	for _, subscription := range subscriptions {
+		list = append(list, toAlertSubscription(subscription))
+	}
+
+	return list, nil
+}
@@ -0,0 +1,22 @@
+package graph
+
+import "fmt"
+
+// threshold validates a check threshold from the api, 0 means the global default.
+func threshold(name string, value int32) (int, error) {
+	if value < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+
+	return int(value), nil
+}
+
+// thresholdOrNil reports unset thresholds as null.
+func thresholdOrNil(value int) *int32 {
+	if value == 0 {
+		return nil
+	}
+
+	result := int32(value)
+	return &result
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/graph/model"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+const phoneCodeTTL = 10 * time.Minute
+
+// Wrong guesses before a pending verification code is thrown away
+const maxPhoneCodeAttempts = 5
+
+func toUser(user db.User) *model.User {
+	result := &model.User{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+	}
+	if user.Phone != "" {
+		result.Phone = &user.Phone
+	}
+
+	return result
+}
+
+func validatePhone(phone string) error {
+	if !e164Pattern.MatchString(phone) {
+		return fmt.Errorf("invalid phone number %q: expected E.164, e.g. +14155550123", phone)
+	}
+
+	return nil
+}
+
+// newPhoneCode returns a random 6 digit code and the hash stored for it.
+func newPhoneCode() (string, string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", "", err
+	}
+
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	return code, hashPhoneCode(code), nil
+}
+
+func hashPhoneCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
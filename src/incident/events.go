@@ -0,0 +1,57 @@
+package incident
+
+import (
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/rs/zerolog"
+)
+
+// Subscribe opens and resolves incidents on state changes and on the resource
+// rules of containers, and records the failure class and remediation attempts
+// during them.
+func Subscribe(logger zerolog.Logger) {
+	events.Subscribe("incidents", func(e events.Event) {
+		handle(logger, e)
+	})
+}
+
+func handle(logger zerolog.Logger, e events.Event) {
+	switch e := e.(type) {
+	case events.StateChanged:
+		switch e.State {
+		case monitor.StateUp:
+			// threshold incidents wait for their rules to clear
+			Resolve(logger, e.MonitorType, e.MonitorID, db.IncidentCauseDown, db.IncidentCauseFlapping)
+		case monitor.StateFlapping:
+			Open(logger, e.MonitorType, e.MonitorID, e.MonitorName, db.IncidentCauseFlapping, string(monitor.StateFlapping))
+		case monitor.StateDown:
+			Open(logger, e.MonitorType, e.MonitorID, e.MonitorName, db.IncidentCauseDown, e.Status)
+		}
+		if e.State != monitor.StateUp && e.Failure != "" {
+			SetFailure(logger, e.MonitorType, e.MonitorID, e.Failure)
+		}
+
+	case events.CheckCompleted:
+		// a crash can turn into a crash loop while the incident is open
+		if !e.Healthy && e.Failure != "" {
+			SetFailure(logger, e.MonitorType, e.MonitorID, e.Failure)
+		}
+
+	case events.ThresholdBreached:
+		Open(logger, e.MonitorType, e.MonitorID, e.MonitorName, db.IncidentCauseThreshold, "threshold: "+e.Rule)
+
+	case events.ThresholdCleared:
+		// a container that is down stays in its incident
+		if e.Firing == 0 && e.State == monitor.StateUp {
+			Resolve(logger, e.MonitorType, e.MonitorID, db.IncidentCauseThreshold)
+		}
+
+	case events.RemediationFailed:
+		RecordAttempt(logger, e.MonitorType, e.MonitorID, notifier.Remediation{Action: e.Action, Error: e.Error})
+
+	case events.RemediationSucceeded:
+		RecordAttempt(logger, e.MonitorType, e.MonitorID, notifier.Remediation{Action: e.Action, Success: true})
+	}
+}
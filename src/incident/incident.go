@@ -0,0 +1,169 @@
+package incident
+
+import (
+	"slices"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// FindOpen returns the open incident of a monitor, or nil.
+func FindOpen(monitorType string, monitorID string) (*db.Incident, error) {
+	var incidents []db.Incident
+	err := db.DB.Where("monitor_type = ? AND monitor_id = ? AND status = ?", monitorType, monitorID, db.IncidentOpen).
+		Order("opened_at DESC").Limit(1).Find(&incidents).Error
+	if err != nil || len(incidents) == 0 {
+		return nil, err
+	}
+
+	return &incidents[0], nil
+}
+
+// Open starts an incident for a monitor that went down, or for the given
+// cause. An incident that is still open, e.g. from before a watchdog restart,
+// is reused.
+func Open(logger zerolog.Logger, monitorType string, monitorID string, monitorName string, cause string, reason string) *db.Incident {
+	existing, err := FindOpen(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to look up incidents of %s", monitorName)
+		return nil
+	}
+	if existing != nil {
+		return existing
+	}
+
+	incident := &db.Incident{
+		ID:          uuid.New().String(),
+		MonitorType: monitorType,
+		MonitorID:   monitorID,
+		MonitorName: monitorName,
+		Status:      db.IncidentOpen,
+		Cause:       cause,
+		Reason:      reason,
+		OpenedAt:    time.Now(),
+	}
+	if err := db.DB.Create(incident).Error; err != nil {
+		logger.Error().Err(err).Msgf("Failed to open incident for %s", monitorName)
+		return nil
+	}
+
+	logger.Warn().Msgf("Opened incident %s for %s", incident.ID, monitorName)
+	Updated(*incident)
+
+	return incident
+}
+
+// RecordAttempt adds a remediation attempt to the open incident of a monitor.
+func RecordAttempt(logger zerolog.Logger, monitorType string, monitorID string, remediation notifier.Remediation) {
+	incident, err := FindOpen(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to look up incidents of monitor %s", monitorID)
+		return
+	}
+	if incident == nil {
+		// resolved by hand while the monitor is still down
+		return
+	}
+
+	attempt := db.RemediationAttempt{
+		IncidentID: incident.ID,
+		Action:     remediation.Action,
+		Success:    remediation.Success,
+		Error:      remediation.Error,
+		At:         time.Now(),
+	}
+	if err := db.DB.Create(&attempt).Error; err != nil {
+		logger.Error().Err(err).Msgf("Failed to record remediation attempt for incident %s", incident.ID)
+		return
+	}
+
+	Updated(*incident)
+}
+
+// SetFailure stores the latest failure class of a container on its open
+// incident, e.g. once a crash turns into a crash loop.
+func SetFailure(logger zerolog.Logger, monitorType string, monitorID string, failure string) {
+	incident, err := FindOpen(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to look up incidents of monitor %s", monitorID)
+		return
+	}
+	if incident == nil || incident.Failure == failure {
+		return
+	}
+
+	incident.Failure = failure
+	if err := save(incident); err != nil {
+		logger.Error().Err(err).Msgf("Failed to update failure of incident %s", incident.ID)
+	}
+}
+
+// Resolve closes the open incident of a monitor that is running again and
+// stores the total downtime. Only an incident opened for one of causes is
+// closed.
+func Resolve(logger zerolog.Logger, monitorType string, monitorID string, causes ...string) *db.Incident {
+	incident, err := FindOpen(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to look up incidents of monitor %s", monitorID)
+		return nil
+	}
+	if incident == nil || !slices.Contains(causes, incident.Cause) {
+		return nil
+	}
+
+	if err := Close(incident); err != nil {
+		logger.Error().Err(err).Msgf("Failed to resolve incident %s", incident.ID)
+		return nil
+	}
+
+	logger.Info().Msgf("Resolved incident %s for %s after %ds", incident.ID, incident.MonitorName, incident.DowntimeSeconds)
+
+	return incident
+}
+
+// Close marks an incident resolved now and stores its downtime.
+func Close(incident *db.Incident) error {
+	now := time.Now()
+	incident.Status = db.IncidentResolved
+	incident.ResolvedAt = &now
+	incident.DowntimeSeconds = int64(now.Sub(incident.OpenedAt).Seconds())
+
+	return save(incident)
+}
+
+// Acknowledge records that userID is handling the incident, which stops
+// its escalation.
+func Acknowledge(incident *db.Incident, userID string) error {
+	now := time.Now()
+	incident.AcknowledgedAt = &now
+	incident.AcknowledgedBy = userID
+
+	return save(incident)
+}
+
+func save(incident *db.Incident) error {
+	if err := db.DB.Omit("Attempts").Save(incident).Error; err != nil {
+		return err
+	}
+
+	Updated(*incident)
+
+	return nil
+}
+
+// Updated announces a change of incident on the event bus.
+func Updated(incident db.Incident) {
+	events.Publish(events.IncidentUpdated{
+		Source: events.Source{
+			MonitorType: incident.MonitorType,
+			MonitorID:   incident.MonitorID,
+			MonitorName: incident.MonitorName,
+			Time:        time.Now(),
+		},
+		IncidentID: incident.ID,
+	})
+}
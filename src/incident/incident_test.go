@@ -0,0 +1,148 @@
+package incident
+
+import (
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/rs/zerolog"
+)
+
+func source(state monitor.State) events.Source {
+	return events.Source{MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web", State: state}
+}
+
+func open(t *testing.T) *db.Incident {
+	t.Helper()
+
+	incident, err := FindOpen(db.MonitorTypeContainer, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return incident
+}
+
+func resolved(t *testing.T) []db.Incident {
+	t.Helper()
+
+	var incidents []db.Incident
+	if err := db.DB.Preload("Attempts").Where("status = ?", db.IncidentResolved).Find(&incidents).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return incidents
+}
+
+func TestDownIncidentLifecycle(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	down := source(monitor.StateDown)
+	down.Status = "exited"
+	down.Failure = "crash"
+	handle(logger, events.StateChanged{Source: down, Previous: monitor.StateSuspect})
+
+	first := open(t)
+	if first == nil || first.Cause != db.IncidentCauseDown || first.Reason != "exited" || first.Failure != "crash" {
+		t.Fatalf("going down opened %+v", first)
+	}
+
+	// a second down, e.g. after a watchdog restart, reuses the incident
+	handle(logger, events.StateChanged{Source: down})
+	if again := open(t); again == nil || again.ID != first.ID {
+		t.Fatalf("incident was not reused: %+v", again)
+	}
+
+	looping := source(monitor.StateDown)
+	looping.Failure = "crash-loop"
+	handle(logger, events.CheckCompleted{Source: looping})
+	handle(logger, events.RemediationFailed{Source: down, Action: "restart container", Error: "no such container"})
+	handle(logger, events.RemediationSucceeded{Source: down, Action: "recreate container"})
+	if current := open(t); current.Failure != "crash-loop" {
+		t.Fatalf("failure = %q, want crash-loop", current.Failure)
+	}
+
+	handle(logger, events.StateChanged{Source: source(monitor.StateUp), Previous: monitor.StateDown})
+	if current := open(t); current != nil {
+		t.Fatalf("recovery left %+v open", current)
+	}
+
+	incidents := resolved(t)
+	if len(incidents) != 1 || incidents[0].ResolvedAt == nil || len(incidents[0].Attempts) != 2 {
+		t.Fatalf("resolved incidents = %+v", incidents)
+	}
+	failed, succeeded := incidents[0].Attempts[0], incidents[0].Attempts[1]
+	if failed.Success || failed.Error != "no such container" || !succeeded.Success || succeeded.Action != "recreate container" {
+		t.Fatalf("attempts = %+v", incidents[0].Attempts)
+	}
+
+	// attempts after the incident was resolved are not recorded anywhere
+	handle(logger, events.RemediationSucceeded{Source: down, Action: "restart container"})
+	var attempts int64
+	db.DB.Model(&db.RemediationAttempt{}).Count(&attempts)
+	if attempts != 2 {
+		t.Fatalf("%d attempts recorded, want 2", attempts)
+	}
+}
+
+func TestFlappingIncident(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	handle(logger, events.StateChanged{Source: source(monitor.StateFlapping), Previous: monitor.StateUp})
+	if incident := open(t); incident == nil || incident.Cause != db.IncidentCauseFlapping {
+		t.Fatalf("flapping opened %+v", incident)
+	}
+
+	handle(logger, events.StateChanged{Source: source(monitor.StateUp), Previous: monitor.StateFlapping})
+	if incident := open(t); incident != nil {
+		t.Fatalf("settling left %+v open", incident)
+	}
+}
+
+func TestThresholdIncidentWaitsForRulesToClear(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	up := source(monitor.StateUp)
+	handle(logger, events.ThresholdBreached{Source: up, Rule: "cpu_percent > 90 for 5m", Value: 97})
+	incident := open(t)
+	if incident == nil || incident.Cause != db.IncidentCauseThreshold || incident.Reason != "threshold: cpu_percent > 90 for 5m" {
+		t.Fatalf("breach opened %+v", incident)
+	}
+
+	steps := []struct {
+		name  string
+		event events.Event
+	}{
+		{"an up state change", events.StateChanged{Source: up, Previous: monitor.StateSuspect}},
+		{"another rule still firing", events.ThresholdCleared{Source: up, Rule: "cpu_percent > 90 for 5m", Firing: 1}},
+		{"a container that is down", events.ThresholdCleared{Source: source(monitor.StateDown), Rule: "cpu_percent > 90 for 5m"}},
+	}
+	for _, step := range steps {
+		handle(logger, step.event)
+		if current := open(t); current == nil || current.ID != incident.ID {
+			t.Fatalf("%s resolved the threshold incident", step.name)
+		}
+	}
+
+	handle(logger, events.ThresholdCleared{Source: up, Rule: "cpu_percent > 90 for 5m"})
+	if current := open(t); current != nil {
+		t.Fatalf("clearing the last rule left %+v open", current)
+	}
+}
+
+func TestThresholdClearedKeepsDownIncident(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	handle(logger, events.StateChanged{Source: source(monitor.StateDown)})
+	handle(logger, events.ThresholdCleared{Source: source(monitor.StateUp), Rule: "memory_percent > 90"})
+
+	if incident := open(t); incident == nil || incident.Cause != db.IncidentCauseDown {
+		t.Fatalf("a cleared rule resolved the down incident: %+v", incident)
+	}
+}
@@ -7,8 +7,14 @@ import (
 	"time"
 
 	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/escalation"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/incident"
+	"github.com/PayCryps/WatchdogGo/src/metrics"
+	"github.com/PayCryps/WatchdogGo/src/monitor/compose"
 	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
 	"github.com/PayCryps/WatchdogGo/src/monitor/process"
+	"github.com/PayCryps/WatchdogGo/src/notifier"
 	"github.com/PayCryps/WatchdogGo/src/server"
 	"github.com/PayCryps/WatchdogGo/src/utils"
 	"github.com/gin-gonic/gin"
@@ -35,9 +41,11 @@ func monitorRoutine(logger zerolog.Logger, stop chan struct{}) {
 	case <-ticker.C:
 		dockerStop := make(chan struct{})
 		processStop := make(chan struct{})
+		composeStop := make(chan struct{})
 
 		go docker.MonitorDocker(logger, dockerStop)
 		go process.MonitorProcess(logger, processStop)
+		go compose.MonitorCompose(logger, composeStop)
 
 	case <-stop:
 		logger.Info().Msg("Monitor thread exiting")
@@ -72,9 +80,17 @@ func main() {
 	db.InitDB(logger)
 	defer db.CloseDB(logger)
 
+	notifier.Setup(logger)
+
+	events.Setup(logger)
+	notifier.Subscribe(logger)
+	incident.Subscribe(logger)
+	metrics.Subscribe()
+
 	stop := make(chan struct{})
 
 	go monitorRoutine(logger, stop)
+	go escalation.RunEscalator(logger, stop)
 	startServer(logger)
 
 	close(stop)
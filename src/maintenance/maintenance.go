@@ -0,0 +1,156 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/rs/zerolog"
+)
+
+// Suppressions are the maintenance windows and silences in effect at one
+// point in time. Monitors they cover are still checked, but neither
+// remediated nor alerted on.
+type Suppressions struct {
+	windows  []db.MaintenanceWindow
+	silences []db.Silence
+}
+
+// Load returns the maintenance windows and silences in effect now.
+func Load(logger zerolog.Logger) Suppressions {
+	now := time.Now()
+	suppressions := Suppressions{}
+
+	var windows []db.MaintenanceWindow
+	err := db.DB.Where("starts_at <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now).Find(&windows).Error
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load maintenance windows")
+	}
+	for _, window := range windows {
+		if WindowActive(window, now) {
+			suppressions.windows = append(suppressions.windows, window)
+		}
+	}
+
+	err = db.DB.Where("starts_at <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now).Find(&suppressions.silences).Error
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load silences")
+	}
+
+	return suppressions
+}
+
+// For returns what suppresses a monitor with the given tags, or an empty
+// string when nothing does.
+func (s Suppressions) For(monitorID string, tags []string) string {
+	for _, window := range s.windows {
+		if inScope(window.MonitorIDs, window.Tags, monitorID, tags) {
+			return "maintenance window " + window.Name
+		}
+	}
+	for _, silence := range s.silences {
+		if inScope(silence.MonitorIDs, silence.Tags, monitorID, tags) {
+			return "silence: " + silence.Reason
+		}
+	}
+
+	return ""
+}
+
+// ForMonitor is For with the tags loaded from the monitor itself.
+func (s Suppressions) ForMonitor(monitorType string, monitorID string) string {
+	if len(s.windows) == 0 && len(s.silences) == 0 {
+		return ""
+	}
+
+	var tags []string
+	switch monitorType {
+	case db.MonitorTypeContainer:
+		var monitor db.ContainerMonitor
+		db.DB.Select("tags").Limit(1).Find(&monitor, "id = ?", monitorID)
+		tags = monitor.Tags
+	case db.MonitorTypeProcess:
+		var monitor db.DbPm2Process
+		db.DB.Select("tags").Limit(1).Find(&monitor, "id = ?", monitorID)
+		tags = monitor.Tags
+	case db.MonitorTypeCompose:
+		var monitor db.ComposeProject
+		db.DB.Select("tags").Limit(1).Find(&monitor, "id = ?", monitorID)
+		tags = monitor.Tags
+	}
+
+	return s.For(monitorID, tags)
+}
+
+func inScope(monitorIDs []string, scopeTags []string, monitorID string, tags []string) bool {
+	if len(monitorIDs) == 0 && len(scopeTags) == 0 {
+		return true
+	}
+	if utils.Contains(monitorIDs, monitorID) {
+		return true
+	}
+	for _, tag := range tags {
+		if utils.Contains(scopeTags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateWindow checks the rule and timezone of a maintenance window.
+func ValidateWindow(window db.MaintenanceWindow) error {
+	if window.DurationMinutes < 1 {
+		return fmt.Errorf("maintenance window must last at least a minute")
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", window.Timezone)
+	}
+	if window.RRule != "" {
+		if _, err := ParseRule(window.RRule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WindowActive reports whether an occurrence of window covers at.
+func WindowActive(window db.MaintenanceWindow, at time.Time) bool {
+	if window.ExpiresAt != nil && !at.Before(*window.ExpiresAt) {
+		return false
+	}
+
+	duration := time.Duration(window.DurationMinutes) * time.Minute
+	if window.RRule == "" {
+		return !at.Before(window.StartsAt) && at.Before(window.StartsAt.Add(duration))
+	}
+
+	rule, err := ParseRule(window.RRule)
+	if err != nil {
+		return false
+	}
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return false
+	}
+
+	// only occurrences starting within duration before at can cover it
+	active := false
+	rule.Occurrences(window.StartsAt.In(location), at.Add(-duration), func(occurrence time.Time) bool {
+		if occurrence.After(at) {
+			return false
+		}
+		active = at.Before(occurrence.Add(duration))
+
+		return !active
+	})
+
+	return active
+}
+
+// SilenceActive reports whether silence is in effect at.
+func SilenceActive(silence db.Silence, at time.Time) bool {
+	return !at.Before(silence.StartsAt) && (silence.ExpiresAt == nil || at.Before(*silence.ExpiresAt))
+}
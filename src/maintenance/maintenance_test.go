@@ -0,0 +1,134 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+func TestWindowActive(t *testing.T) {
+	start := time.Date(2026, 1, 4, 2, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		window db.MaintenanceWindow
+		at     time.Time
+		want   bool
+	}{
+		{
+			name:   "one-off before start",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 60},
+			at:     start.Add(-time.Minute),
+		},
+		{
+			name:   "one-off within",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 60},
+			at:     start.Add(59 * time.Minute),
+			want:   true,
+		},
+		{
+			name:   "one-off after",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 60},
+			at:     start.Add(time.Hour),
+		},
+		{
+			name:   "weekly within a later occurrence",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 120, RRule: "FREQ=WEEKLY;BYDAY=SU", Timezone: "UTC"},
+			at:     start.AddDate(0, 0, 21).Add(90 * time.Minute),
+			want:   true,
+		},
+		{
+			name:   "weekly on another day",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 120, RRule: "FREQ=WEEKLY;BYDAY=SU", Timezone: "UTC"},
+			at:     start.AddDate(0, 0, 22),
+		},
+		{
+			name:   "expired",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 120, RRule: "FREQ=DAILY", Timezone: "UTC", ExpiresAt: &expires},
+			at:     expires.Add(2*time.Hour + 30*time.Minute),
+		},
+		{
+			name:   "past its count",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 120, RRule: "FREQ=DAILY;COUNT=3", Timezone: "UTC"},
+			at:     start.AddDate(0, 0, 3),
+		},
+		{
+			name:   "longer than its interval",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 150, RRule: "FREQ=HOURLY", Timezone: "UTC"},
+			at:     start.AddDate(2, 0, 0).Add(30 * time.Minute),
+			want:   true,
+		},
+		{
+			name:   "old hourly window between occurrences",
+			window: db.MaintenanceWindow{StartsAt: start.AddDate(-10, 0, 0), DurationMinutes: 10, RRule: "FREQ=HOURLY;INTERVAL=2", Timezone: "UTC"},
+			at:     start.Add(time.Hour + 5*time.Minute),
+		},
+		{
+			name:   "old hourly window within an occurrence",
+			window: db.MaintenanceWindow{StartsAt: start.AddDate(-10, 0, 0), DurationMinutes: 10, RRule: "FREQ=HOURLY;INTERVAL=2", Timezone: "UTC"},
+			at:     start.Add(2*time.Hour + 5*time.Minute),
+			want:   true,
+		},
+		{
+			name:   "invalid rule",
+			window: db.MaintenanceWindow{StartsAt: start, DurationMinutes: 60, RRule: "FREQ=YEARLY", Timezone: "UTC"},
+			at:     start,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WindowActive(tt.window, tt.at); got != tt.want {
+				t.Fatalf("WindowActive() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowActiveFollowsTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+
+	// 03:00 in Berlin, daily, through the switch to summer time on March 29th
+	window := db.MaintenanceWindow{
+		StartsAt:        time.Date(2026, 3, 1, 3, 0, 0, 0, berlin),
+		DurationMinutes: 30,
+		RRule:           "FREQ=DAILY",
+		Timezone:        "Europe/Berlin",
+	}
+
+	if !WindowActive(window, time.Date(2026, 4, 2, 3, 10, 0, 0, berlin)) {
+		t.Fatal("window should follow local time after the daylight saving switch")
+	}
+	if WindowActive(window, time.Date(2026, 4, 2, 2, 10, 0, 0, berlin)) {
+		t.Fatal("window should not be active an hour early")
+	}
+}
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		monitorIDs []string
+		scopeTags  []string
+		tags       []string
+		want       bool
+	}{
+		{name: "empty scope covers every monitor", want: true},
+		{name: "listed monitor", monitorIDs: []string{"a", "b"}, want: true},
+		{name: "unlisted monitor", monitorIDs: []string{"b"}},
+		{name: "shared tag", scopeTags: []string{"db"}, tags: []string{"web", "db"}, want: true},
+		{name: "no shared tag", scopeTags: []string{"db"}, tags: []string{"web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inScope(tt.monitorIDs, tt.scopeTags, "a", tt.tags); got != tt.want {
+				t.Fatalf("inScope() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is the subset of RFC 5545 recurrence rules maintenance windows
+// support: FREQ (HOURLY, DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY for
+// weekly rules, COUNT and UNTIL.
+type Rule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    *time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRule parses a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU".
+func ParseRule(rule string) (*Rule, error) {
+	parsed := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			parsed.Freq = strings.ToUpper(value)
+			switch parsed.Freq {
+			case "HOURLY", "DAILY", "WEEKLY", "MONTHLY":
+			default:
+				return nil, fmt.Errorf("unsupported rrule frequency %q", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid rrule interval %q", value)
+			}
+			parsed.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid rrule count %q", value)
+			}
+			parsed.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			parsed.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported rrule day %q", day)
+				}
+				parsed.ByDay = append(parsed.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	if parsed.Freq == "" {
+		return nil, fmt.Errorf("rrule needs a FREQ")
+	}
+	if len(parsed.ByDay) > 0 && parsed.Freq != "WEEKLY" {
+		return nil, fmt.Errorf("BYDAY is only supported for weekly rules")
+	}
+	if parsed.Count > 0 && parsed.Until != nil {
+		return nil, fmt.Errorf("rrule must not have both COUNT and UNTIL")
+	}
+
+	// Monday first, as with the default WKST
+	sort.Slice(parsed.ByDay, func(i, j int) bool {
+		return mondayOffset(parsed.ByDay[i]) < mondayOffset(parsed.ByDay[j])
+	})
+
+	return parsed, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return until, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid rrule until %q", value)
+}
+
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// Occurrences calls fn with every occurrence starting at start, in order,
+// until fn returns false or the rule ends. Whole periods before from are
+// skipped without calling fn, so a few occurrences before from may still be
+// passed but none after it are missed. COUNT keeps counting the skipped ones.
+func (r Rule) Occurrences(start time.Time, from time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if r.Until != nil && occurrence.After(*r.Until) {
+			return false
+		}
+		emitted++
+		if r.Count > 0 && emitted > r.Count {
+			return false
+		}
+
+		return fn(occurrence)
+	}
+
+	switch r.Freq {
+	case "HOURLY":
+		skipped := periodsBefore(start, from, time.Duration(r.Interval)*time.Hour)
+		emitted = skipped
+		for i := skipped; ; i++ {
+			if !emit(start.Add(time.Duration(i*r.Interval) * time.Hour)) {
+				return
+			}
+		}
+
+	case "DAILY":
+		// a day is 24 hours give or take a daylight saving shift, which the
+		// period kept in hand by periodsBefore makes up for
+		skipped := periodsBefore(start, from, time.Duration(r.Interval)*24*time.Hour)
+		emitted = skipped
+		for i := skipped; ; i++ {
+			if !emit(start.AddDate(0, 0, i*r.Interval)) {
+				return
+			}
+		}
+
+	case "WEEKLY":
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+
+		monday := start.AddDate(0, 0, -mondayOffset(start.Weekday()))
+		skipped := periodsBefore(monday, from, time.Duration(r.Interval)*7*24*time.Hour)
+		if skipped > 0 {
+			// the first week only has the days from start on
+			for _, day := range days {
+				if !monday.AddDate(0, 0, mondayOffset(day)).Before(start) {
+					emitted++
+				}
+			}
+			emitted += (skipped - 1) * len(days)
+		}
+		for week := skipped; ; week++ {
+			base := monday.AddDate(0, 0, 7*week*r.Interval)
+			for _, day := range days {
+				occurrence := base.AddDate(0, 0, mondayOffset(day))
+				if occurrence.Before(start) {
+					continue
+				}
+				if !emit(occurrence) {
+					return
+				}
+			}
+		}
+
+	case "MONTHLY":
+		for month := 0; ; month++ {
+			occurrence := time.Date(start.Year(), start.Month()+time.Month(month*r.Interval), start.Day(),
+				start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+			// months without the day are skipped
+			if occurrence.Day() != start.Day() {
+				continue
+			}
+			if !emit(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// periodsBefore returns how many whole periods after start are over before
+// from, keeping one period in hand.
+func periodsBefore(start time.Time, from time.Time, period time.Duration) int {
+	if !from.After(start) {
+		return 0
+	}
+
+	skipped := int(from.Sub(start)/period) - 1
+	if skipped < 0 {
+		return 0
+	}
+
+	return skipped
+}
@@ -0,0 +1,161 @@
+package maintenance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	until := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule    string
+		want    *Rule
+		wantErr bool
+	}{
+		{rule: "FREQ=DAILY", want: &Rule{Freq: "DAILY", Interval: 1}},
+		{rule: "RRULE:FREQ=hourly;INTERVAL=6", want: &Rule{Freq: "HOURLY", Interval: 6}},
+		{rule: "FREQ=WEEKLY;BYDAY=SU,MO,SA", want: &Rule{Freq: "WEEKLY", Interval: 1, ByDay: []time.Weekday{time.Monday, time.Saturday, time.Sunday}}},
+		{rule: "FREQ=MONTHLY;COUNT=3", want: &Rule{Freq: "MONTHLY", Interval: 1, Count: 3}},
+		{rule: "FREQ=DAILY;UNTIL=20261231", want: &Rule{Freq: "DAILY", Interval: 1, Until: &until}},
+		{rule: "FREQ=DAILY;UNTIL=20261231T000000Z", want: &Rule{Freq: "DAILY", Interval: 1, Until: &until}},
+		{rule: "", wantErr: true},
+		{rule: "INTERVAL=2", wantErr: true},
+		{rule: "FREQ=YEARLY", wantErr: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=-1", wantErr: true},
+		{rule: "FREQ=DAILY;UNTIL=tomorrow", wantErr: true},
+		{rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: true},
+		{rule: "FREQ=DAILY;BYHOUR=3", wantErr: true},
+		{rule: "FREQ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := ParseRule(tt.rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRule(%q) = %+v, want an error", tt.rule, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRule(%q): %s", tt.rule, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseRule(%q) = %+v, want %+v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+// occurrences collects the occurrences of rule from start on, at most limit.
+func occurrences(t *testing.T, rule string, start time.Time, from time.Time, limit int) []time.Time {
+	t.Helper()
+
+	parsed, err := ParseRule(rule)
+	if err != nil {
+		t.Fatalf("ParseRule(%q): %s", rule, err)
+	}
+
+	list := []time.Time{}
+	parsed.Occurrences(start, from, func(occurrence time.Time) bool {
+		list = append(list, occurrence)
+		return len(list) < limit
+	})
+
+	return list
+}
+
+func TestOccurrences(t *testing.T) {
+	// a Wednesday
+	start := time.Date(2026, 1, 7, 22, 0, 0, 0, time.UTC)
+	day := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule string
+		want []time.Time
+	}{
+		{rule: "FREQ=HOURLY;INTERVAL=2", want: []time.Time{day(1, 7, 22), day(1, 8, 0), day(1, 8, 2)}},
+		{rule: "FREQ=DAILY;COUNT=2", want: []time.Time{day(1, 7, 22), day(1, 8, 22)}},
+		{rule: "FREQ=DAILY;UNTIL=20260109T000000Z", want: []time.Time{day(1, 7, 22), day(1, 8, 22)}},
+		{rule: "FREQ=WEEKLY", want: []time.Time{day(1, 7, 22), day(1, 14, 22), day(1, 21, 22)}},
+		// Monday is before start and skipped in the first week
+		{rule: "FREQ=WEEKLY;BYDAY=MO,FR", want: []time.Time{day(1, 9, 22), day(1, 12, 22), day(1, 16, 22)}},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE", want: []time.Time{day(1, 7, 22), day(1, 21, 22), day(2, 4, 22)}},
+		{rule: "FREQ=MONTHLY", want: []time.Time{day(1, 7, 22), day(2, 7, 22), day(3, 7, 22)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got := occurrences(t, tt.rule, start, start, 3)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesMonthlySkipsShortMonths(t *testing.T) {
+	start := time.Date(2026, 1, 31, 3, 0, 0, 0, time.UTC)
+
+	got := occurrences(t, "FREQ=MONTHLY", start, start, 3)
+	want := []time.Time{start, time.Date(2026, 3, 31, 3, 0, 0, 0, time.UTC), time.Date(2026, 5, 31, 3, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// Skipping ahead to from must not change the occurrences after it, nor what
+// COUNT allows.
+func TestOccurrencesFrom(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	start := time.Date(2020, 3, 4, 1, 30, 0, 0, berlin)
+	from := time.Date(2026, 10, 25, 2, 0, 0, 0, berlin)
+
+	rules := []string{
+		"FREQ=HOURLY",
+		"FREQ=HOURLY;INTERVAL=5",
+		"FREQ=DAILY",
+		"FREQ=DAILY;INTERVAL=3",
+		"FREQ=WEEKLY;BYDAY=MO,TH,SU",
+		"FREQ=WEEKLY;INTERVAL=3;BYDAY=TU",
+		"FREQ=DAILY;COUNT=2425",
+		"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=700",
+	}
+
+	for _, rule := range rules {
+		t.Run(rule, func(t *testing.T) {
+			afterFrom := func(list []time.Time) []time.Time {
+				kept := []time.Time{}
+				for _, occurrence := range list {
+					if !occurrence.Before(from) {
+						kept = append(kept, occurrence)
+					}
+				}
+				return kept
+			}
+
+			all := afterFrom(occurrences(t, rule, start, start, 100000))
+			skipped := afterFrom(occurrences(t, rule, start, from, 100000))
+
+			if len(all) > 20 {
+				all = all[:20]
+			}
+			if len(skipped) > 20 {
+				skipped = skipped[:20]
+			}
+			if !reflect.DeepEqual(all, skipped) {
+				t.Fatalf("got %v, want %v", skipped, all)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/gin-gonic/gin"
+)
+
+// metric is a set of samples sharing a name, keyed by their rendered labels.
+type metric struct {
+	help    string
+	kind    string
+	samples map[string]float64
+}
+
+var (
+	mu      sync.Mutex
+	metrics = map[string]*metric{}
+)
+
+var states = []monitor.State{monitor.StateUp, monitor.StateSuspect, monitor.StateDown, monitor.StateFlapping}
+
+func register(name string, kind string, help string) {
+	metrics[name] = &metric{help: help, kind: kind, samples: map[string]float64{}}
+}
+
+func init() {
+	register("watchdog_checks_total", "counter", "Checks of a monitor by result.")
+	register("watchdog_monitor_state", "gauge", "1 for the current state of a monitor.")
+	register("watchdog_state_changes_total", "counter", "State changes of a monitor by new state.")
+	register("watchdog_remediations_total", "counter", "Remediation actions by outcome.")
+	register("watchdog_threshold_breaches_total", "counter", "Resource rules of a monitor that fired.")
+}
+
+func labels(source events.Source, pairs ...string) string {
+	all := append([]string{"monitor_type", source.MonitorType, "monitor", source.MonitorName}, pairs...)
+
+	rendered := []string{}
+	for i := 0; i+1 < len(all); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(all[i+1])
+		rendered = append(rendered, fmt.Sprintf(`%s="%s"`, all[i], value))
+	}
+
+	return "{" + strings.Join(rendered, ",") + "}"
+}
+
+func add(name string, labels string, value float64) {
+	metrics[name].samples[labels] += value
+}
+
+func set(name string, labels string, value float64) {
+	metrics[name].samples[labels] = value
+}
+
+// Subscribe counts the checks, state changes, remediations and threshold
+// breaches published by the monitors.
+func Subscribe() {
+	events.Subscribe("metrics", func(e events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch e := e.(type) {
+		case events.CheckCompleted:
+			result := "unhealthy"
+			if e.Healthy {
+				result = "healthy"
+			}
+			add("watchdog_checks_total", labels(e.Source, "result", result), 1)
+
+			for _, state := range states {
+				value := 0.0
+				if state == e.State {
+					value = 1
+				}
+				set("watchdog_monitor_state", labels(e.Source, "state", string(state)), value)
+			}
+
+		case events.StateChanged:
+			add("watchdog_state_changes_total", labels(e.Source, "state", string(e.State)), 1)
+
+		case events.RemediationStarted:
+			add("watchdog_remediations_total", labels(e.Source, "action", e.Action, "outcome", "started"), 1)
+
+		case events.RemediationFailed:
+			add("watchdog_remediations_total", labels(e.Source, "action", e.Action, "outcome", "failed"), 1)
+
+		case events.RemediationSucceeded:
+			add("watchdog_remediations_total", labels(e.Source, "action", e.Action, "outcome", "succeeded"), 1)
+
+		case events.RemediationExhausted:
+			add("watchdog_remediations_total", labels(e.Source, "action", "", "outcome", "exhausted"), 1)
+
+		case events.ThresholdBreached:
+			add("watchdog_threshold_breaches_total", labels(e.Source, "rule", e.Rule), 1)
+		}
+	})
+}
+
+// Write renders every metric in the Prometheus text format.
+func Write(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := []string{}
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := metrics[name]
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, m.help, name, m.kind)
+
+		samples := []string{}
+		for labels := range m.samples {
+			samples = append(samples, labels)
+		}
+		sort.Strings(samples)
+
+		for _, labels := range samples {
+			fmt.Fprintf(w, "%s%s %g\n", name, labels, m.samples[labels])
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP watchdog_events_dropped_total Events dropped because a subscriber fell behind.\n# TYPE watchdog_events_dropped_total counter\n")
+	dropped := events.Dropped()
+	subscribers := []string{}
+	for name := range dropped {
+		subscribers = append(subscribers, name)
+	}
+	sort.Strings(subscribers)
+	for _, name := range subscribers {
+		fmt.Fprintf(w, "watchdog_events_dropped_total{subscriber=\"%s\"} %d\n", name, dropped[name])
+	}
+}
+
+// Handler serves the metrics for Prometheus to scrape.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4")
+		Write(c.Writer)
+	}
+}
@@ -0,0 +1,102 @@
+package check
+
+import (
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/PayCryps/WatchdogGo/src/restart"
+	"github.com/rs/zerolog"
+)
+
+// Target is the monitor a check loop looked at.
+type Target struct {
+	// Kind names the monitor in logs, e.g. "Container" or "Process"
+	Kind string
+	Type string
+	ID   string
+	Name string
+	Tags []string
+}
+
+// Check is a single check of a monitor, recorded on its tracker.
+type Check struct {
+	Target     Target
+	Transition monitor.Transition
+	// Suppressed names the maintenance window or silence covering the monitor
+	Suppressed string
+	Source     events.Source
+}
+
+// Observe records the outcome of a check, publishes it on the event bus and
+// resets the restart policy of monitors that came back up. source builds the
+// event source once the new state is known, detail describes a failed check
+// in logs.
+func Observe(logger zerolog.Logger, tracker *monitor.Tracker, thresholds monitor.Thresholds, suppressions maintenance.Suppressions, target Target, healthy bool, detail string, source func(monitor.State) events.Source) Check {
+	downSince := tracker.Since(target.ID)
+	transition := tracker.Observe(target.ID, healthy, thresholds)
+
+	check := Check{
+		Target:     target,
+		Transition: transition,
+		// suppressed monitors keep their state and incidents, without remediation or alerts
+		Suppressed: suppressions.For(target.ID, target.Tags),
+		Source:     source(transition.Current),
+	}
+	events.Publish(events.CheckCompleted{Source: check.Source, Healthy: healthy, Suppressed: check.Suppressed})
+
+	if !transition.Changed() {
+		return check
+	}
+
+	changed := events.StateChanged{Source: check.Source, Previous: transition.Previous, Suppressed: check.Suppressed}
+	// the first check of a monitor that is up ends no downtime
+	if transition.Current == monitor.StateUp && transition.Seen && transition.Previous != monitor.StateUp {
+		changed.Downtime = time.Since(downSince)
+	}
+
+	switch transition.Current {
+	case monitor.StateSuspect:
+		logger.Warn().Msgf("%s %s failed a check (%s)", target.Kind, target.Name, detail)
+	case monitor.StateFlapping:
+		logger.Warn().Msgf("%s %s is flapping, restarts are suspended", target.Kind, target.Name)
+	}
+	events.Publish(changed)
+
+	if transition.Current == monitor.StateUp {
+		restart.Reset(logger, target.Type, target.ID)
+	}
+
+	return check
+}
+
+// Down reports whether the monitor is down and may be remediated. Monitors
+// down during a maintenance window or silence are left alone.
+func (c Check) Down(logger zerolog.Logger) bool {
+	if c.Transition.Current != monitor.StateDown {
+		return false
+	}
+
+	if c.Suppressed != "" {
+		if c.Transition.Changed() {
+			logger.Info().Msgf("%s %s is down during %s, not restarting", c.Target.Kind, c.Target.Name, c.Suppressed)
+		}
+		return false
+	}
+
+	return true
+}
+
+// Remediate runs remediate when the restart policy allows it and records the
+// restart when remediate took action. failed tells crashes from clean exits.
+func (c *Check) Remediate(logger zerolog.Logger, policy db.RestartPolicy, failed bool, remediate func(source *events.Source) bool) {
+	if !restart.Allow(logger, c.Source, policy, failed) {
+		return
+	}
+
+	if remediate(&c.Source) {
+		restart.Record(logger, c.Target.Type, c.Target.ID, policy)
+	}
+}
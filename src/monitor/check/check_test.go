@@ -0,0 +1,70 @@
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/rs/zerolog"
+)
+
+// observe runs a check of the web container and returns the StateChanged it
+// published, if any.
+func observe(t *testing.T, tracker *monitor.Tracker, healthy bool) (events.StateChanged, bool) {
+	t.Helper()
+
+	published, unsubscribe := events.Channel(t.Name())
+	defer unsubscribe()
+
+	target := Target{Kind: "Container", Type: db.MonitorTypeContainer, ID: "web", Name: "web"}
+	thresholds := monitor.Thresholds{Failures: 1, Successes: 1}
+	Observe(zerolog.Nop(), tracker, thresholds, maintenance.Suppressions{}, target, healthy, "exited", func(state monitor.State) events.Source {
+		return events.Source{MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web", State: state}
+	})
+
+	// CheckCompleted always comes first
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-published:
+			if changed, ok := event.(events.StateChanged); ok {
+				return changed, true
+			}
+		case <-timeout:
+			return events.StateChanged{}, false
+		}
+	}
+}
+
+func TestObserveDowntime(t *testing.T) {
+	dbtest.Use(t)
+	tracker := monitor.NewTracker()
+
+	first, ok := observe(t, tracker, true)
+	if !ok || first.State != monitor.StateUp || first.Previous != "" {
+		t.Fatalf("first check published %+v, %v", first, ok)
+	}
+	if first.Downtime != 0 {
+		t.Fatalf("first check of an up monitor has a downtime of %s", first.Downtime)
+	}
+
+	if down, ok := observe(t, tracker, false); !ok || down.State != monitor.StateDown || down.Downtime != 0 {
+		t.Fatalf("going down published %+v, %v", down, ok)
+	}
+
+	up, ok := observe(t, tracker, true)
+	if !ok || up.State != monitor.StateUp || up.Previous != monitor.StateDown {
+		t.Fatalf("recovery published %+v, %v", up, ok)
+	}
+	if up.Downtime <= 0 || up.Downtime > time.Minute {
+		t.Fatalf("downtime = %s", up.Downtime)
+	}
+
+	if _, ok := observe(t, tracker, true); ok {
+		t.Fatal("a check that changes nothing should not publish StateChanged")
+	}
+}
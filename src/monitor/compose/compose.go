@@ -0,0 +1,138 @@
+package compose
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/PayCryps/WatchdogGo/src/monitor/check"
+	"github.com/PayCryps/WatchdogGo/src/monitor/docker"
+	"github.com/PayCryps/WatchdogGo/src/restart"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+var tracker = monitor.NewTracker()
+
+func MonitorCompose(logger zerolog.Logger, composeStop chan struct{}) {
+	logger.Info().Msg("Compose Monitor thread started")
+
+	HaltTime := os.Getenv("COMPOSE_HALT_TIME")
+	if HaltTime == "" {
+		HaltTime = "10"
+	}
+	haltDuration, err := strconv.Atoi(HaltTime)
+	if err != nil {
+		logger.Error().Msgf("Error converting COMPOSE_HALT_TIME to an integer: %s", err)
+		return
+	}
+	ticker := time.NewTicker(time.Second * time.Duration(haltDuration))
+	defer ticker.Stop()
+
+	thresholds := monitor.DefaultThresholds(logger)
+	restartDefaults := restart.Defaults(logger)
+
+	for {
+		select {
+		case <-ticker.C:
+			monitorProjects(logger, thresholds, restartDefaults)
+
+		case <-composeStop:
+			logger.Info().Msg("Compose thread exiting")
+			return
+		}
+	}
+}
+
+func monitorProjects(logger zerolog.Logger, thresholds monitor.Thresholds, restartDefaults db.RestartPolicy) {
+	var projects []db.ComposeProject
+	if err := db.DB.Where("enabled = ?", true).Find(&projects).Error; err != nil {
+		logger.Error().Err(err).Msg("Failed to load compose projects")
+		return
+	}
+	if len(projects) == 0 {
+		return
+	}
+
+	dockerCli := docker.CreateDockerClient()
+	containers, err := ListProjectContainers(dockerCli)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list compose containers")
+		return
+	}
+	suppressions := maintenance.Load(logger)
+
+	for _, desired := range projects {
+		rememberServices(&desired, containers[desired.Name], logger)
+		status := Status(desired, containers[desired.Name])
+		rememberTemplates(dockerCli, &desired, status, logger)
+
+		target := check.Target{Kind: "Compose project", Type: db.MonitorTypeCompose, ID: desired.ID, Name: desired.Name, Tags: desired.Tags}
+		result := check.Observe(logger, tracker, thresholds.For(desired.FailureThreshold, desired.RecoveryThreshold), suppressions, target, status.Healthy(), status.Summary(), func(state monitor.State) events.Source {
+			return projectSource(desired, status, state)
+		})
+
+		if !result.Down(logger) {
+			continue
+		}
+
+		policy := restart.Effective(desired.RestartPolicy, restartDefaults)
+		// missing replicas are failures whatever the exit code of the stopped ones
+		result.Remediate(logger, policy, true, func(source *events.Source) bool {
+			return remediateProject(dockerCli, desired, status, source, logger)
+		})
+	}
+}
+
+// rememberServices stores the services of a project without a service list
+// the first time they are found.
+func rememberServices(project *db.ComposeProject, containers []types.Container, logger zerolog.Logger) {
+	if len(project.Services) > 0 {
+		return
+	}
+
+	discovered := DiscoverServices(*project, containers)
+	if slices.Equal(discovered, project.DiscoveredServices) {
+		return
+	}
+
+	project.DiscoveredServices = discovered
+	err := db.DB.Model(&db.ComposeProject{ID: project.ID}).
+		Select("DiscoveredServices").
+		Updates(db.ComposeProject{DiscoveredServices: discovered}).Error
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to store the services of compose project %s", project.Name)
+	}
+}
+
+// remediateProject restarts or recreates the replicas of every unhealthy
+// service. It returns false when no action was taken.
+func remediateProject(cli *client.Client, desired db.ComposeProject, status ProjectStatus, source *events.Source, logger zerolog.Logger) bool {
+	acted := false
+	for _, service := range status.Services {
+		if !service.Healthy() && remediateService(cli, desired, service, source, logger) {
+			acted = true
+		}
+	}
+
+	return acted
+}
+
+func projectSource(desired db.ComposeProject, status ProjectStatus, state monitor.State) events.Source {
+	return events.Source{
+		MonitorType: db.MonitorTypeCompose,
+		MonitorID:   desired.ID,
+		MonitorName: desired.Name,
+		Critical:    desired.Critical,
+		State:       state,
+		Since:       tracker.Since(desired.ID),
+		Status:      status.Summary(),
+		Time:        time.Now(),
+	}
+}
@@ -0,0 +1,228 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+// serviceTemplate returns the stored container a service's replicas are
+// copied from.
+func serviceTemplate(project db.ComposeProject, service string) (types.ContainerJSON, bool) {
+	var template types.ContainerJSON
+	raw, ok := project.ServiceTemplates[service]
+	if !ok {
+		return template, false
+	}
+	if err := json.Unmarshal(raw, &template); err != nil || template.ContainerJSONBase == nil {
+		return template, false
+	}
+
+	return template, true
+}
+
+// rememberTemplates inspects a container of every service whose template is
+// missing or was replaced, e.g. by docker compose up with a new image, and
+// stores the templates on the project so they outlive a watchdog restart.
+func rememberTemplates(cli *client.Client, project *db.ComposeProject, status ProjectStatus, logger zerolog.Logger) {
+	changed := false
+	for _, service := range status.Services {
+		template, _ := serviceTemplate(*project, service.Name)
+		if len(service.Containers) == 0 || hasContainer(service.Containers, template) {
+			continue
+		}
+
+		inspect, err := cli.ContainerInspect(context.Background(), service.Containers[0].ID)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to inspect a container of compose service %s/%s", project.Name, service.Name)
+			continue
+		}
+		raw, err := json.Marshal(inspect)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to encode the template of compose service %s/%s", project.Name, service.Name)
+			continue
+		}
+
+		if project.ServiceTemplates == nil {
+			project.ServiceTemplates = map[string]json.RawMessage{}
+		}
+		project.ServiceTemplates[service.Name] = raw
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	err := db.DB.Model(&db.ComposeProject{ID: project.ID}).
+		Select("ServiceTemplates").
+		Updates(db.ComposeProject{ServiceTemplates: project.ServiceTemplates}).Error
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to store the service templates of compose project %s", project.Name)
+	}
+}
+
+func hasContainer(replicas []types.Container, template types.ContainerJSON) bool {
+	if template.ContainerJSONBase == nil {
+		return false
+	}
+
+	for _, replica := range replicas {
+		if replica.ID == template.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remediateService restarts the stopped replicas of a service and recreates
+// the missing ones. It returns false when no action was taken.
+func remediateService(cli *client.Client, desired db.ComposeProject, service ServiceStatus, source *events.Source, logger zerolog.Logger) bool {
+	project := desired.Name
+	acted := false
+
+	for _, replica := range service.Containers {
+		if replica.State == "running" {
+			continue
+		}
+
+		name := replica.ID[:12]
+		if len(replica.Names) > 0 {
+			name = strings.TrimPrefix(replica.Names[0], "/")
+		}
+		logger.Warn().Msgf("Restarting %s of compose service %s/%s", name, project, service.Name)
+		events.Remediate(source, fmt.Sprintf("restart %s of service %s", name, service.Name), func() error {
+			return cli.ContainerRestart(context.Background(), replica.ID, container.StopOptions{})
+		})
+		acted = true
+	}
+
+	missing := service.Desired - len(service.Containers)
+	if missing <= 0 {
+		return acted
+	}
+
+	if os.Getenv("DOCKER_START") == "FALSE" {
+		logger.Info().Msg("DOCKER_START is set to false, not recreating compose replicas")
+		return acted
+	}
+
+	template, ok := serviceTemplate(desired, service.Name)
+	if !ok {
+		logger.Error().Msgf("Compose service %s/%s has no container to copy, run docker compose up", project, service.Name)
+		return acted
+	}
+
+	next := nextContainerNumber(service.Containers, template)
+	for i := 0; i < missing; i++ {
+		number := next + i
+		logger.Warn().Msgf("Recreating replica %d of compose service %s/%s", number, project, service.Name)
+		events.Remediate(source, fmt.Sprintf("recreate replica %d of service %s", number, service.Name), func() error {
+			containerID, err := createReplica(cli, project, service.Name, template, number)
+			source.ContainerID = containerID
+			return err
+		})
+		acted = true
+	}
+
+	return acted
+}
+
+// nextContainerNumber is the number after the highest replica number in use.
+func nextContainerNumber(replicas []types.Container, template types.ContainerJSON) int {
+	highest := 0
+	for _, replica := range replicas {
+		if number, err := strconv.Atoi(replica.Labels[ContainerNumberLabel]); err == nil && number > highest {
+			highest = number
+		}
+	}
+	if len(replicas) == 0 && template.Config != nil {
+		// the template is gone too, its number is free again
+		if number, err := strconv.Atoi(template.Config.Labels[ContainerNumberLabel]); err == nil {
+			return number
+		}
+	}
+
+	return highest + 1
+}
+
+// createReplica creates and starts a copy of template with the given replica
+// number. The compose labels are kept so docker compose still manages it.
+func createReplica(cli *client.Client, project string, service string, template types.ContainerJSON, number int) (string, error) {
+	ctx := context.Background()
+
+	if template.ContainerJSONBase == nil || template.Config == nil || template.HostConfig == nil {
+		return "", fmt.Errorf("incomplete template for service %s", service)
+	}
+
+	config := *template.Config
+	config.Hostname = ""
+	config.Labels = map[string]string{}
+	for key, value := range template.Config.Labels {
+		config.Labels[key] = value
+	}
+	config.Labels[ContainerNumberLabel] = strconv.Itoa(number)
+
+	// compose v1 named containers project_service_1, v2 project-service-1
+	separator := "-"
+	if strings.HasPrefix(strings.TrimPrefix(template.Name, "/"), project+"_") {
+		separator = "_"
+	}
+	name := strings.Join([]string{project, service, strconv.Itoa(number)}, separator)
+
+	// older daemons only accept one network on create, the others are connected afterwards
+	primary := string(template.HostConfig.NetworkMode)
+	endpoints := map[string]*network.EndpointSettings{}
+	if template.NetworkSettings != nil {
+		for networkName, endpoint := range template.NetworkSettings.Networks {
+			endpoints[networkName] = &network.EndpointSettings{Aliases: replicaAliases(endpoint.Aliases, template), Links: endpoint.Links}
+		}
+	}
+	networking := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+	if endpoint, ok := endpoints[primary]; ok {
+		networking.EndpointsConfig[primary] = endpoint
+	}
+
+	created, err := cli.ContainerCreate(ctx, &config, template.HostConfig, networking, nil, name)
+	if err != nil {
+		return "", err
+	}
+
+	for networkName, endpoint := range endpoints {
+		if networkName == primary {
+			continue
+		}
+		if err := cli.NetworkConnect(ctx, networkName, created.ID, endpoint); err != nil {
+			return created.ID, err
+		}
+	}
+
+	return created.ID, cli.ContainerStart(ctx, created.ID, container.StartOptions{})
+}
+
+// replicaAliases drops the aliases naming the template container itself,
+// keeping the service name.
+func replicaAliases(aliases []string, template types.ContainerJSON) []string {
+	name := strings.TrimPrefix(template.Name, "/")
+
+	kept := []string{}
+	for _, alias := range aliases {
+		if alias == name || strings.HasPrefix(template.ID, alias) {
+			continue
+		}
+		kept = append(kept, alias)
+	}
+
+	return kept
+}
@@ -0,0 +1,56 @@
+package compose
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestNextContainerNumber(t *testing.T) {
+	template := types.ContainerJSON{Config: &container.Config{Labels: map[string]string{ContainerNumberLabel: "2"}}}
+
+	tests := []struct {
+		name     string
+		replicas []types.Container
+		template types.ContainerJSON
+		number   int
+	}{
+		{"after the highest replica", []types.Container{replica("web", "3", "running"), replica("web", "1", "exited")}, template, 4},
+		{"unnumbered replicas", []types.Container{{Labels: map[string]string{ServiceLabel: "web"}}}, template, 1},
+		{"no replicas reuses the number of the template", nil, template, 2},
+		{"no replicas and no template", nil, types.ContainerJSON{}, 1},
+	}
+
+	for _, test := range tests {
+		if got := nextContainerNumber(test.replicas, test.template); got != test.number {
+			t.Errorf("%s: nextContainerNumber() = %d, want %d", test.name, got, test.number)
+		}
+	}
+}
+
+func TestServiceTemplate(t *testing.T) {
+	inspect := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: "web-1"},
+		Config:            &container.Config{Image: "shop/web:1.0"},
+	}
+	raw, err := json.Marshal(inspect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := db.ComposeProject{ServiceTemplates: map[string]json.RawMessage{"web": raw, "broken": json.RawMessage(`{}`)}}
+
+	template, ok := serviceTemplate(project, "web")
+	if !ok || template.ID != "web-1" || template.Config.Image != "shop/web:1.0" {
+		t.Fatalf("serviceTemplate(web) = %+v, %v", template, ok)
+	}
+	if _, ok := serviceTemplate(project, "broken"); ok {
+		t.Fatal("an incomplete template was returned")
+	}
+	if _, ok := serviceTemplate(project, "db"); ok {
+		t.Fatal("a template was returned for a service never seen")
+	}
+}
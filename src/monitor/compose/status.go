@@ -0,0 +1,81 @@
+package compose
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// ListProjectContainers returns the containers of every compose project,
+// stopped ones included, grouped by project name.
+func ListProjectContainers(cli *client.Client) (map[string][]types.Container, error) {
+	containers, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ProjectLabel)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projects := map[string][]types.Container{}
+	for _, listed := range containers {
+		project := listed.Labels[ProjectLabel]
+		projects[project] = append(projects[project], listed)
+	}
+
+	return projects, nil
+}
+
+// DiscoverServices adds the services of containers to the ones discovered
+// before, sorted by name. Services disappearing completely stay expected.
+func DiscoverServices(project db.ComposeProject, containers []types.Container) []string {
+	services := append([]string{}, project.DiscoveredServices...)
+	for _, listed := range containers {
+		if service := listed.Labels[ServiceLabel]; !slices.Contains(services, service) {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	return services
+}
+
+// Status groups the containers of a project by service and counts their
+// running replicas against the services of the monitor.
+func Status(project db.ComposeProject, containers []types.Container) ProjectStatus {
+	byService := map[string][]types.Container{}
+	for _, listed := range containers {
+		service := listed.Labels[ServiceLabel]
+		byService[service] = append(byService[service], listed)
+	}
+
+	expected := project.Services
+	if len(expected) == 0 {
+		// without a list every service found is expected once
+		for _, service := range DiscoverServices(project, containers) {
+			expected = append(expected, db.ComposeService{Name: service, Replicas: 1})
+		}
+	}
+
+	status := ProjectStatus{Project: project.Name}
+	for _, spec := range expected {
+		service := ServiceStatus{Name: spec.Name, Desired: spec.Replicas, Containers: byService[spec.Name]}
+		if service.Desired < 1 {
+			service.Desired = 1
+		}
+		for _, listed := range service.Containers {
+			if listed.State == "running" {
+				service.Running++
+			}
+		}
+		status.Services = append(status.Services, service)
+	}
+
+	return status
+}
@@ -0,0 +1,148 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/docker/docker/api/types"
+	"github.com/rs/zerolog"
+)
+
+func replica(service string, number string, state string) types.Container {
+	return types.Container{
+		ID:     service + "-" + number,
+		State:  state,
+		Labels: map[string]string{ProjectLabel: "shop", ServiceLabel: service, ContainerNumberLabel: number},
+	}
+}
+
+// counts returns the running and desired replicas per service.
+func counts(status ProjectStatus) map[string][2]int {
+	got := map[string][2]int{}
+	for _, service := range status.Services {
+		got[service.Name] = [2]int{service.Running, service.Desired}
+	}
+	return got
+}
+
+func TestStatus(t *testing.T) {
+	containers := []types.Container{
+		replica("web", "1", "running"),
+		replica("web", "2", "exited"),
+		replica("worker", "1", "running"),
+		replica("cache", "1", "running"),
+	}
+
+	tests := []struct {
+		name    string
+		project db.ComposeProject
+		counts  map[string][2]int
+		healthy bool
+		summary string
+	}{
+		{
+			name:    "listed services with replicas",
+			project: db.ComposeProject{Name: "shop", Services: []db.ComposeService{{Name: "web", Replicas: 2}, {Name: "worker", Replicas: 1}}},
+			counts:  map[string][2]int{"web": {1, 2}, "worker": {1, 1}},
+			summary: "web 1/2",
+		},
+		{
+			name:    "services missing completely",
+			project: db.ComposeProject{Name: "shop", Services: []db.ComposeService{{Name: "web"}, {Name: "db", Replicas: 1}}},
+			counts:  map[string][2]int{"web": {1, 1}, "db": {0, 1}},
+			summary: "db 0/1",
+		},
+		{
+			name:    "every service found is expected once",
+			project: db.ComposeProject{Name: "shop"},
+			counts:  map[string][2]int{"cache": {1, 1}, "web": {1, 1}, "worker": {1, 1}},
+			healthy: true,
+			summary: "healthy",
+		},
+		{
+			name:    "services found before stay expected",
+			project: db.ComposeProject{Name: "shop", DiscoveredServices: []string{"db", "web"}},
+			counts:  map[string][2]int{"cache": {1, 1}, "db": {0, 1}, "web": {1, 1}, "worker": {1, 1}},
+			summary: "db 0/1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := Status(test.project, containers)
+			if got := counts(status); !reflect.DeepEqual(got, test.counts) {
+				t.Fatalf("services = %v, want %v", got, test.counts)
+			}
+			if status.Healthy() != test.healthy || status.Summary() != test.summary {
+				t.Fatalf("healthy %v %q, want %v %q", status.Healthy(), status.Summary(), test.healthy, test.summary)
+			}
+		})
+	}
+}
+
+func TestProjectStatusSummary(t *testing.T) {
+	tests := []struct {
+		status  ProjectStatus
+		healthy bool
+		summary string
+	}{
+		{ProjectStatus{}, false, "no services"},
+		{ProjectStatus{Services: []ServiceStatus{{Name: "web", Desired: 2, Running: 3}}}, true, "healthy"},
+		{ProjectStatus{Services: []ServiceStatus{
+			{Name: "worker", Desired: 1},
+			{Name: "web", Desired: 2, Running: 1},
+			{Name: "cache", Desired: 1, Running: 1},
+		}}, false, "web 1/2, worker 0/1"},
+	}
+
+	for _, test := range tests {
+		if test.status.Healthy() != test.healthy || test.status.Summary() != test.summary {
+			t.Errorf("%+v: healthy %v %q, want %v %q", test.status, test.status.Healthy(), test.status.Summary(), test.healthy, test.summary)
+		}
+	}
+}
+
+func TestDiscoverServices(t *testing.T) {
+	project := db.ComposeProject{DiscoveredServices: []string{"db", "web"}}
+	containers := []types.Container{replica("worker", "1", "running"), replica("web", "1", "running"), replica("web", "2", "running")}
+
+	if got := DiscoverServices(project, containers); !reflect.DeepEqual(got, []string{"db", "web", "worker"}) {
+		t.Fatalf("DiscoverServices() = %v", got)
+	}
+	if !reflect.DeepEqual(project.DiscoveredServices, []string{"db", "web"}) {
+		t.Fatalf("DiscoverServices changed the project: %v", project.DiscoveredServices)
+	}
+}
+
+func TestRememberServices(t *testing.T) {
+	dbtest.Use(t)
+
+	project := db.ComposeProject{ID: "shop", Name: "shop", Enabled: true}
+	if err := db.DB.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rememberServices(&project, []types.Container{replica("web", "1", "running"), replica("db", "1", "running")}, zerolog.Nop())
+	// the db container was removed, it is still expected
+	rememberServices(&project, []types.Container{replica("web", "1", "running")}, zerolog.Nop())
+
+	var stored db.ComposeProject
+	if err := db.DB.First(&stored, "id = ?", "shop").Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored.DiscoveredServices, []string{"db", "web"}) {
+		t.Fatalf("stored services = %v", stored.DiscoveredServices)
+	}
+	if got := counts(Status(stored, []types.Container{replica("web", "1", "running")})); got["db"] != [2]int{0, 1} {
+		t.Fatalf("services after a restart = %v, want db expected", got)
+	}
+
+	// listed services are not tracked
+	listed := db.ComposeProject{ID: "blog", Name: "blog", Services: []db.ComposeService{{Name: "web"}}}
+	rememberServices(&listed, []types.Container{replica("db", "1", "running")}, zerolog.Nop())
+	if listed.DiscoveredServices != nil {
+		t.Fatalf("discovered %v for a project listing its services", listed.DiscoveredServices)
+	}
+}
@@ -0,0 +1,67 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Labels docker compose sets on the containers of a project
+const (
+	ProjectLabel         = "com.docker.compose.project"
+	ServiceLabel         = "com.docker.compose.service"
+	ContainerNumberLabel = "com.docker.compose.container-number"
+)
+
+// ServiceStatus is the state of the replicas of a compose service.
+type ServiceStatus struct {
+	Name    string
+	Desired int
+	Running int
+	// Containers of the service, stopped ones included
+	Containers []types.Container
+}
+
+func (s ServiceStatus) Healthy() bool {
+	return s.Running >= s.Desired
+}
+
+// ProjectStatus is the state of every expected service of a compose project.
+type ProjectStatus struct {
+	Project  string
+	Services []ServiceStatus
+}
+
+// Healthy reports whether every service runs its desired replicas.
+func (p ProjectStatus) Healthy() bool {
+	for _, service := range p.Services {
+		if !service.Healthy() {
+			return false
+		}
+	}
+
+	return len(p.Services) > 0
+}
+
+// Summary lists the unhealthy services with their running replicas, e.g.
+// "web 1/2, worker 0/1", or "healthy".
+func (p ProjectStatus) Summary() string {
+	if len(p.Services) == 0 {
+		return "no services"
+	}
+
+	parts := []string{}
+	for _, service := range p.Services {
+		if !service.Healthy() {
+			parts = append(parts, fmt.Sprintf("%s %d/%d", service.Name, service.Running, service.Desired))
+		}
+	}
+	if len(parts) == 0 {
+		return "healthy"
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, ", ")
+}
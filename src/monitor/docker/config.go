@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog"
+)
+
+// GetMonitoredContainers loads the enabled container monitors from the database.
+func GetMonitoredContainers(logger zerolog.Logger) []ContainerDetails {
+	var monitors []db.ContainerMonitor
+	if err := db.DB.Where("enabled = ?", true).Find(&monitors).Error; err != nil {
+		logger.Error().Err(err).Msg("Failed to load container monitors")
+		return nil
+	}
+
+	containers := []ContainerDetails{}
+	for _, monitor := range monitors {
+		details, err := ContainerDetailsFromMonitor(monitor)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Skipping container monitor %s", monitor.Name)
+			continue
+		}
+		containers = append(containers, details)
+	}
+
+	return containers
+}
+
+// ContainerDetailsFromMonitor builds the docker create configs for a stored monitor.
+func ContainerDetailsFromMonitor(monitor db.ContainerMonitor) (ContainerDetails, error) {
+	exposedPorts, portBindings, err := ParsePorts(monitor.Ports)
+	if err != nil {
+		return ContainerDetails{}, err
+	}
+
+	mounts, err := ParseMounts(monitor.Mounts)
+	if err != nil {
+		return ContainerDetails{}, err
+	}
+
+	labels := map[string]string{}
+	for key, value := range monitor.Labels {
+		labels[key] = value
+	}
+	labels[MonitorIDLabel] = monitor.ID
+
+	restartPolicy, err := ParseDockerRestart(monitor.DockerRestart)
+	if err != nil {
+		return ContainerDetails{}, err
+	}
+
+	config := container.Config{
+		Image:        monitor.Image,
+		Env:          monitor.Env,
+		Cmd:          monitor.Command,
+		Entrypoint:   monitor.Entrypoint,
+		User:         monitor.User,
+		WorkingDir:   monitor.WorkingDir,
+		Labels:       labels,
+		ExposedPorts: exposedPorts,
+	}
+	if check := monitor.Healthcheck; check != nil {
+		config.Healthcheck = &container.HealthConfig{
+			Test:        check.Test,
+			Interval:    check.Interval,
+			Timeout:     check.Timeout,
+			StartPeriod: check.StartPeriod,
+			Retries:     check.Retries,
+		}
+	}
+
+	hostConfig := container.HostConfig{
+		Mounts:        mounts,
+		PortBindings:  portBindings,
+		NetworkMode:   container.NetworkMode(monitor.Network),
+		RestartPolicy: restartPolicy,
+	}
+
+	return ContainerDetails{
+		MonitorID:         monitor.ID,
+		Name:              "/" + monitor.Name,
+		Critical:          monitor.Critical,
+		Tags:              monitor.Tags,
+		FailureThreshold:  monitor.FailureThreshold,
+		RecoveryThreshold: monitor.RecoveryThreshold,
+		RestartPolicy:     monitor.RestartPolicy,
+		UnhealthyGrace:    time.Duration(monitor.UnhealthyGraceSeconds) * time.Second,
+		StatRules:         monitor.StatRules,
+		Configs:           config,
+		HostConfig:        hostConfig,
+	}, nil
+}
+
+// ParseDockerRestart parses a docker restart policy in the docker cli syntax,
+// e.g. "always" or "on-failure:3".
+func ParseDockerRestart(spec string) (container.RestartPolicy, error) {
+	if spec == "" {
+		return container.RestartPolicy{}, nil
+	}
+
+	name, count, hasCount := strings.Cut(spec, ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	if hasCount {
+		retries, err := strconv.Atoi(count)
+		if err != nil || retries < 0 {
+			return container.RestartPolicy{}, fmt.Errorf("invalid docker restart policy %q", spec)
+		}
+		policy.MaximumRetryCount = retries
+	}
+	if err := container.ValidateRestartPolicy(policy); err != nil {
+		return container.RestartPolicy{}, fmt.Errorf("invalid docker restart policy %q: %w", spec, err)
+	}
+
+	return policy, nil
+}
+
+// ParsePorts parses port specs in the docker cli syntax, e.g. "127.0.0.1:8080:80/tcp".
+func ParsePorts(ports []string) (nat.PortSet, nat.PortMap, error) {
+	exposedPorts, portBindings, err := nat.ParsePortSpecs(ports)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid port spec: %w", err)
+	}
+
+	return exposedPorts, portBindings, nil
+}
+
+// ParseMounts parses "source:target[:ro]" specs. Absolute sources are bind
+// mounts, anything else is treated as a named volume.
+func ParseMounts(specs []string) ([]mount.Mount, error) {
+	mounts := []mount.Mount{}
+
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid mount %q: expected source:target[:ro]", spec)
+		}
+
+		source, target := parts[0], parts[1]
+		if source == "" || !filepath.IsAbs(target) {
+			return nil, fmt.Errorf("invalid mount %q: target must be an absolute path", spec)
+		}
+
+		readOnly := false
+		if len(parts) == 3 {
+			switch parts[2] {
+			case "ro":
+				readOnly = true
+			case "rw":
+			default:
+				return nil, fmt.Errorf("invalid mount %q: unknown mode %s", spec, parts[2])
+			}
+		}
+
+		mountType := mount.TypeVolume
+		if filepath.IsAbs(source) {
+			mountType = mount.TypeBind
+		}
+
+		mounts = append(mounts, mount.Mount{
+			Type:     mountType,
+			Source:   source,
+			Target:   target,
+			ReadOnly: readOnly,
+		})
+	}
+
+	return mounts, nil
+}
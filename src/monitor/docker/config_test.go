@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog"
+)
+
+func TestGetMonitoredContainers(t *testing.T) {
+	dbtest.Use(t)
+
+	monitors := []db.ContainerMonitor{
+		{
+			ID:      "postgres",
+			Name:    "postgres",
+			Image:   "postgres:15.0-alpine",
+			Env:     []string{"POSTGRES_PASSWORD=secret"},
+			Ports:   []string{"127.0.0.1:5432:5432"},
+			Mounts:  []string{"data:/var/lib/postgresql/data"},
+			Network: "watchdog",
+			Labels:  map[string]string{"team": "core"},
+			Enabled: true,
+		},
+		{ID: "disabled", Name: "disabled", Image: "nginx", Enabled: false},
+		{ID: "broken", Name: "broken", Image: "nginx", Ports: []string{"not-a-port"}, Enabled: true},
+	}
+	for _, monitor := range monitors {
+		if err := db.DB.Create(&monitor).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	containers := GetMonitoredContainers(zerolog.Nop())
+	if len(containers) != 1 {
+		t.Fatalf("loaded %d containers, want only the enabled valid one", len(containers))
+	}
+
+	postgres := containers[0]
+	if postgres.MonitorID != "postgres" || postgres.Name != "/postgres" || postgres.Configs.Image != "postgres:15.0-alpine" {
+		t.Fatalf("loaded %+v", postgres)
+	}
+	if len(postgres.Configs.Env) != 1 || postgres.Configs.Env[0] != "POSTGRES_PASSWORD=secret" {
+		t.Fatalf("env = %v", postgres.Configs.Env)
+	}
+	if postgres.Configs.Labels["team"] != "core" || postgres.Configs.Labels[MonitorIDLabel] != "postgres" {
+		t.Fatalf("labels = %v", postgres.Configs.Labels)
+	}
+	if _, ok := postgres.Configs.ExposedPorts["5432/tcp"]; !ok {
+		t.Fatalf("exposed ports = %v", postgres.Configs.ExposedPorts)
+	}
+	if string(postgres.HostConfig.NetworkMode) != "watchdog" || len(postgres.HostConfig.Mounts) != 1 {
+		t.Fatalf("host config = %+v", postgres.HostConfig)
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []string
+		exposed  nat.Port
+		hostIP   string
+		hostPort string
+		err      bool
+	}{
+		{"container port only", []string{"80"}, "80/tcp", "", "", false},
+		{"host and container port", []string{"8080:80"}, "80/tcp", "", "8080", false},
+		{"host ip", []string{"127.0.0.1:8080:80/tcp"}, "80/tcp", "127.0.0.1", "8080", false},
+		{"udp", []string{"53:53/udp"}, "53/udp", "", "53", false},
+		{"invalid", []string{"http"}, "", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exposed, bindings, err := ParsePorts(test.ports)
+			if (err != nil) != test.err {
+				t.Fatalf("ParsePorts(%v) error = %v", test.ports, err)
+			}
+			if test.err {
+				return
+			}
+
+			if _, ok := exposed[test.exposed]; !ok {
+				t.Fatalf("exposed = %v, want %s", exposed, test.exposed)
+			}
+			if test.hostPort == "" {
+				return
+			}
+			binding := bindings[test.exposed]
+			if len(binding) != 1 || binding[0].HostIP != test.hostIP || binding[0].HostPort != test.hostPort {
+				t.Fatalf("bindings = %v", bindings)
+			}
+		})
+	}
+}
+
+func TestParseMounts(t *testing.T) {
+	tests := []struct {
+		spec  string
+		mount mount.Mount
+		err   bool
+	}{
+		{"data:/var/lib/data", mount.Mount{Type: mount.TypeVolume, Source: "data", Target: "/var/lib/data"}, false},
+		{"/etc/app:/etc/app:ro", mount.Mount{Type: mount.TypeBind, Source: "/etc/app", Target: "/etc/app", ReadOnly: true}, false},
+		{"/srv:/srv:rw", mount.Mount{Type: mount.TypeBind, Source: "/srv", Target: "/srv"}, false},
+		{"data", mount.Mount{}, true},
+		{"data:relative", mount.Mount{}, true},
+		{":/data", mount.Mount{}, true},
+		{"data:/data:rx", mount.Mount{}, true},
+		{"a:/b:ro:extra", mount.Mount{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			mounts, err := ParseMounts([]string{test.spec})
+			if (err != nil) != test.err {
+				t.Fatalf("ParseMounts(%s) error = %v", test.spec, err)
+			}
+			if test.err {
+				return
+			}
+
+			if len(mounts) != 1 || mounts[0] != test.mount {
+				t.Fatalf("ParseMounts(%s) = %+v, want %+v", test.spec, mounts, test.mount)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// Labels that opt a container in to auto-discovery and configure its monitor
+const (
+	EnableLabel        = "watchdog.enable"
+	RestartPolicyLabel = "watchdog.restart-policy"
+	// NotifyLabel names the escalation policy of the monitor
+	NotifyLabel   = "watchdog.notify"
+	CriticalLabel = "watchdog.critical"
+	// TagsLabel is a comma separated list of tags
+	TagsLabel = "watchdog.tags"
+)
+
+// composeProjectLabel is compose.ProjectLabel, the compose package imports this one
+const composeProjectLabel = "com.docker.compose.project"
+
+// DiscoverContainers creates monitors for the containers labelled with
+// watchdog.enable=true that are not monitored yet. Their config is
+// snapshotted on first sight so they can be recreated later. Replicas of
+// compose projects with a monitor are left to the compose loop. Set
+// DOCKER_DISCOVERY to FALSE to only monitor containers registered by hand.
+func DiscoverContainers(cli *client.Client, logger zerolog.Logger) {
+	if os.Getenv("DOCKER_DISCOVERY") == "FALSE" {
+		return
+	}
+
+	containers, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", EnableLabel+"=true")),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list containers for discovery")
+		return
+	}
+
+	// replicas of monitored compose projects are left to the compose loop
+	var composeProjects []string
+	if err := db.DB.Model(&db.ComposeProject{}).Pluck("name", &composeProjects).Error; err != nil {
+		logger.Error().Err(err).Msg("Failed to load compose projects for discovery")
+		return
+	}
+
+	for _, listed := range containers {
+		if len(listed.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(listed.Names[0], "/")
+		if project := listed.Labels[composeProjectLabel]; project != "" && slices.Contains(composeProjects, project) {
+			continue
+		}
+
+		// disabled monitors count too, so disabling a discovered monitor sticks
+		var count int64
+		query := db.DB.Model(&db.ContainerMonitor{}).Where("name = ?", name)
+		if monitorID := listed.Labels[MonitorIDLabel]; monitorID != "" {
+			query = query.Or("id = ?", monitorID)
+		}
+		if err := query.Count(&count).Error; err != nil {
+			logger.Error().Err(err).Msgf("Failed to look up the monitor of container %s", name)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+
+		inspect, err := cli.ContainerInspect(context.Background(), listed.ID)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to inspect discovered container %s", name)
+			continue
+		}
+
+		monitor, err := monitorFromContainer(name, inspect, logger)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Skipping discovered container %s", name)
+			continue
+		}
+
+		if err := db.DB.Create(&monitor).Error; err != nil {
+			logger.Error().Err(err).Msgf("Failed to create a monitor for discovered container %s", name)
+			continue
+		}
+
+		logger.Info().Msgf("Discovered container %s, monitoring it as %s", name, monitor.ID)
+	}
+}
+
+// monitorFromContainer snapshots the config of a running container and
+// applies its watchdog labels.
+func monitorFromContainer(name string, inspect types.ContainerJSON, logger zerolog.Logger) (db.ContainerMonitor, error) {
+	if inspect.ContainerJSONBase == nil || inspect.Config == nil || inspect.HostConfig == nil {
+		return db.ContainerMonitor{}, fmt.Errorf("incomplete inspect result")
+	}
+
+	labels := map[string]string{}
+	for key, value := range inspect.Config.Labels {
+		if key != MonitorIDLabel {
+			labels[key] = value
+		}
+	}
+
+	monitor := db.ContainerMonitor{
+		ID:            uuid.New().String(),
+		Name:          name,
+		Image:         inspect.Config.Image,
+		Env:           inspect.Config.Env,
+		Command:       inspect.Config.Cmd,
+		Ports:         portSpecs(inspect.HostConfig.PortBindings),
+		Mounts:        mountSpecs(inspect.Mounts),
+		Network:       string(inspect.HostConfig.NetworkMode),
+		Labels:        labels,
+		Enabled:       true,
+		Critical:      labels[CriticalLabel] == "true",
+		AutoManaged:   true,
+		Entrypoint:    inspect.Config.Entrypoint,
+		User:          inspect.Config.User,
+		WorkingDir:    inspect.Config.WorkingDir,
+		Healthcheck:   healthcheckFromConfig(inspect.Config.Healthcheck),
+		DockerRestart: dockerRestartSpec(inspect.HostConfig.RestartPolicy),
+	}
+
+	if mode := labels[RestartPolicyLabel]; mode != "" {
+		if mode != db.RestartAlways && mode != db.RestartOnFailure && mode != db.RestartNever {
+			return db.ContainerMonitor{}, fmt.Errorf("invalid %s %q", RestartPolicyLabel, mode)
+		}
+		monitor.RestartPolicy.Mode = mode
+	}
+
+	for _, tag := range strings.Split(labels[TagsLabel], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			monitor.Tags = append(monitor.Tags, tag)
+		}
+	}
+
+	if policyName := labels[NotifyLabel]; policyName != "" {
+		var policy db.EscalationPolicy
+		err := db.DB.First(&policy, "name = ?", policyName).Error
+		switch {
+		case err == nil:
+			monitor.EscalationPolicyID = &policy.ID
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			logger.Warn().Msgf("Container %s asks to notify %s, but there is no escalation policy with that name", name, policyName)
+		default:
+			return db.ContainerMonitor{}, err
+		}
+	}
+
+	return monitor, nil
+}
+
+// healthcheckFromConfig keeps the healthcheck of a container, if it has one.
+func healthcheckFromConfig(config *container.HealthConfig) *db.Healthcheck {
+	if config == nil || len(config.Test) == 0 {
+		return nil
+	}
+
+	return &db.Healthcheck{
+		Test:        config.Test,
+		Interval:    config.Interval,
+		Timeout:     config.Timeout,
+		StartPeriod: config.StartPeriod,
+		Retries:     config.Retries,
+	}
+}
+
+// dockerRestartSpec turns a docker restart policy into the docker cli syntax.
+func dockerRestartSpec(policy container.RestartPolicy) string {
+	if policy.Name == "" || policy.Name == container.RestartPolicyDisabled {
+		return ""
+	}
+	if policy.IsOnFailure() && policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+
+	return string(policy.Name)
+}
+
+// portSpecs turns published ports back into the docker cli syntax.
+func portSpecs(bindings map[nat.Port][]nat.PortBinding) []string {
+	specs := []string{}
+	seen := map[string]bool{}
+	for port, published := range bindings {
+		for _, binding := range published {
+			spec := binding.HostPort + ":" + string(port)
+			// docker binds every address twice, for IPv4 and IPv6
+			if binding.HostIP != "" && binding.HostIP != "0.0.0.0" && binding.HostIP != "::" {
+				spec = binding.HostIP + ":" + spec
+			}
+			if binding.HostPort == "" {
+				spec = string(port)
+			}
+			if !seen[spec] {
+				seen[spec] = true
+				specs = append(specs, spec)
+			}
+		}
+	}
+	sort.Strings(specs)
+
+	return specs
+}
+
+// mountSpecs turns bind mounts and volumes back into source:target[:ro] specs.
+func mountSpecs(mounts []types.MountPoint) []string {
+	specs := []string{}
+	for _, point := range mounts {
+		source := point.Source
+		switch point.Type {
+		case mount.TypeVolume:
+			source = point.Name
+		case mount.TypeBind:
+		default:
+			continue
+		}
+
+		spec := source + ":" + point.Destination
+		if !point.RW {
+			spec += ":ro"
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs
+}
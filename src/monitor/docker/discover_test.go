@@ -0,0 +1,272 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog"
+)
+
+func discoveredInspect() types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:   "abc",
+			Name: "/api",
+			HostConfig: &container.HostConfig{
+				NetworkMode:   "backend",
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+				PortBindings:  nat.PortMap{"80/tcp": {{HostIP: "0.0.0.0", HostPort: "8080"}, {HostIP: "::", HostPort: "8080"}}},
+			},
+		},
+		Mounts: []types.MountPoint{
+			{Type: mount.TypeVolume, Name: "data", Destination: "/data", RW: true},
+			{Type: mount.TypeBind, Source: "/etc/api", Destination: "/config"},
+		},
+		Config: &container.Config{
+			Image:      "registry.local/api:1.2",
+			Env:        []string{"PORT=80"},
+			Cmd:        []string{"serve"},
+			Entrypoint: []string{"/entrypoint.sh"},
+			User:       "1000:1000",
+			WorkingDir: "/srv",
+			Healthcheck: &container.HealthConfig{
+				Test:     []string{"CMD", "curl", "-f", "http://localhost/health"},
+				Interval: 30 * time.Second,
+				Timeout:  5 * time.Second,
+				Retries:  3,
+			},
+			Labels: map[string]string{
+				EnableLabel:        "true",
+				RestartPolicyLabel: "on-failure",
+				TagsLabel:          "api, backend",
+				MonitorIDLabel:     "stale",
+			},
+		},
+	}
+}
+
+func TestMonitorFromContainerRecreatesTheSameContainer(t *testing.T) {
+	inspect := discoveredInspect()
+
+	monitor, err := monitorFromContainer("api", inspect, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("monitorFromContainer: %s", err)
+	}
+	if monitor.RestartPolicy.Mode != "on-failure" || !reflect.DeepEqual(monitor.Tags, []string{"api", "backend"}) {
+		t.Fatalf("labels were not applied: %+v", monitor)
+	}
+	if _, ok := monitor.Labels[MonitorIDLabel]; ok {
+		t.Fatal("the identity label of the old container should not be copied")
+	}
+
+	details, err := ContainerDetailsFromMonitor(monitor)
+	if err != nil {
+		t.Fatalf("ContainerDetailsFromMonitor: %s", err)
+	}
+
+	config := details.Configs
+	if !reflect.DeepEqual(config.Entrypoint, inspect.Config.Entrypoint) || config.User != "1000:1000" || config.WorkingDir != "/srv" {
+		t.Fatalf("entrypoint, user or working dir lost: %+v", config)
+	}
+	if !reflect.DeepEqual(config.Healthcheck, inspect.Config.Healthcheck) {
+		t.Fatalf("healthcheck = %+v, want %+v", config.Healthcheck, inspect.Config.Healthcheck)
+	}
+	if !reflect.DeepEqual(config.Cmd, inspect.Config.Cmd) || !reflect.DeepEqual(config.Env, inspect.Config.Env) || config.Image != inspect.Config.Image {
+		t.Fatalf("image, env or cmd lost: %+v", config)
+	}
+	if config.Labels[MonitorIDLabel] != monitor.ID {
+		t.Fatal("recreated containers should carry the monitor id")
+	}
+
+	host := details.HostConfig
+	if host.RestartPolicy != inspect.HostConfig.RestartPolicy {
+		t.Fatalf("restart policy = %+v, want %+v", host.RestartPolicy, inspect.HostConfig.RestartPolicy)
+	}
+	if host.NetworkMode != "backend" || !reflect.DeepEqual(host.PortBindings, nat.PortMap{"80/tcp": {{HostPort: "8080"}}}) {
+		t.Fatalf("network or ports lost: %+v", host)
+	}
+	if len(host.Mounts) != 2 || host.Mounts[0].Type != mount.TypeVolume || host.Mounts[1].Type != mount.TypeBind || !host.Mounts[1].ReadOnly {
+		t.Fatalf("mounts = %+v", host.Mounts)
+	}
+}
+
+func TestDockerRestartSpec(t *testing.T) {
+	tests := []struct {
+		policy container.RestartPolicy
+		spec   string
+	}{
+		{container.RestartPolicy{}, ""},
+		{container.RestartPolicy{Name: container.RestartPolicyDisabled}, ""},
+		{container.RestartPolicy{Name: container.RestartPolicyAlways}, "always"},
+		{container.RestartPolicy{Name: container.RestartPolicyUnlessStopped}, "unless-stopped"},
+		{container.RestartPolicy{Name: container.RestartPolicyOnFailure}, "on-failure"},
+		{container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5}, "on-failure:5"},
+	}
+
+	for _, test := range tests {
+		if spec := dockerRestartSpec(test.policy); spec != test.spec {
+			t.Errorf("dockerRestartSpec(%+v) = %q, want %q", test.policy, spec, test.spec)
+		}
+		policy, err := ParseDockerRestart(test.spec)
+		if err != nil {
+			t.Errorf("ParseDockerRestart(%q): %s", test.spec, err)
+		}
+		if test.spec != "" && policy != test.policy {
+			t.Errorf("ParseDockerRestart(%q) = %+v, want %+v", test.spec, policy, test.policy)
+		}
+	}
+
+	for _, invalid := range []string{"sometimes", "always:3", "on-failure:x", "on-failure:-1"} {
+		if _, err := ParseDockerRestart(invalid); err == nil {
+			t.Errorf("ParseDockerRestart(%q) should fail", invalid)
+		}
+	}
+}
+
+// dockerStub serves the container list and inspect endpoints of the docker API.
+func dockerStub(t *testing.T, containers []types.Container, inspect map[string]types.ContainerJSON) *client.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1.43")
+		switch {
+		case path == "/containers/json":
+			json.NewEncoder(w).Encode(containers)
+		case strings.HasPrefix(path, "/containers/") && strings.HasSuffix(path, "/json"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json")
+			found, ok := inspect[id]
+			if !ok {
+				http.Error(w, `{"message":"no such container"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(found)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithVersion("1.43"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cli.Close() })
+
+	return cli
+}
+
+func TestDiscoverContainersSkipsMonitoredComposeProjects(t *testing.T) {
+	dbtest.Use(t)
+
+	if err := db.DB.Create(&db.ComposeProject{ID: "shop", Name: "shop", Enabled: true}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := map[string]string{EnableLabel: "true"}
+	replica := map[string]string{EnableLabel: "true", composeProjectLabel: "shop"}
+	other := map[string]string{EnableLabel: "true", composeProjectLabel: "blog"}
+	containers := []types.Container{
+		{ID: "api", Names: []string{"/api"}, Labels: enabled},
+		{ID: "shop-web", Names: []string{"/shop-web-1"}, Labels: replica},
+		{ID: "blog-web", Names: []string{"/blog-web-1"}, Labels: other},
+	}
+	inspect := map[string]types.ContainerJSON{}
+	for _, listed := range containers {
+		found := discoveredInspect()
+		found.ID = listed.ID
+		found.Config.Labels = listed.Labels
+		inspect[listed.ID] = found
+	}
+
+	DiscoverContainers(dockerStub(t, containers, inspect), zerolog.Nop())
+
+	var names []string
+	if err := db.DB.Model(&db.ContainerMonitor{}).Order("name").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"api", "blog-web-1"}) {
+		t.Fatalf("discovered %v, want api and the replica of the unmonitored project", names)
+	}
+}
+
+func TestDiscoverContainers(t *testing.T) {
+	dbtest.Use(t)
+
+	existing := []db.ContainerMonitor{
+		{ID: "m-api", Name: "api", Image: "api", Enabled: false},
+		{ID: "m-web", Name: "web", Image: "nginx", Enabled: true},
+	}
+	for _, monitor := range existing {
+		if err := db.DB.Create(&monitor).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.DB.Create(&db.EscalationPolicy{ID: "p-oncall", Name: "on-call"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	containers := []types.Container{
+		// disabled monitors are not discovered again
+		{ID: "api", Names: []string{"/api"}, Labels: map[string]string{EnableLabel: "true"}},
+		// renamed containers are matched by their identity label
+		{ID: "web", Names: []string{"/web-renamed"}, Labels: map[string]string{EnableLabel: "true", MonitorIDLabel: "m-web"}},
+		{ID: "worker", Names: []string{"/worker"}, Labels: map[string]string{EnableLabel: "true", CriticalLabel: "true", NotifyLabel: "on-call"}},
+		{ID: "invalid", Names: []string{"/invalid"}, Labels: map[string]string{EnableLabel: "true", RestartPolicyLabel: "sometimes"}},
+	}
+	inspect := map[string]types.ContainerJSON{}
+	for _, listed := range containers {
+		found := discoveredInspect()
+		found.ID = listed.ID
+		found.Name = listed.Names[0]
+		found.Config.Labels = listed.Labels
+		inspect[listed.ID] = found
+	}
+	cli := dockerStub(t, containers, inspect)
+
+	t.Setenv("DOCKER_DISCOVERY", "FALSE")
+	DiscoverContainers(cli, zerolog.Nop())
+	var count int64
+	db.DB.Model(&db.ContainerMonitor{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("discovered containers with DOCKER_DISCOVERY set to FALSE, %d monitors", count)
+	}
+
+	t.Setenv("DOCKER_DISCOVERY", "")
+	DiscoverContainers(cli, zerolog.Nop())
+	DiscoverContainers(cli, zerolog.Nop())
+
+	var monitors []db.ContainerMonitor
+	if err := db.DB.Order("name").Find(&monitors).Error; err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, monitor := range monitors {
+		names = append(names, monitor.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"api", "web", "worker"}) {
+		t.Fatalf("monitors %v, want worker discovered once", names)
+	}
+
+	worker := monitors[2]
+	if !worker.Enabled || !worker.AutoManaged || !worker.Critical {
+		t.Fatalf("discovered monitor %+v", worker)
+	}
+	if worker.EscalationPolicyID == nil || *worker.EscalationPolicyID != "p-oncall" {
+		t.Fatalf("escalation policy = %v, want the one named by %s", worker.EscalationPolicyID, NotifyLabel)
+	}
+	if monitors[0].Enabled {
+		t.Fatal("discovery enabled a disabled monitor")
+	}
+}
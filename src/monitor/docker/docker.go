@@ -5,16 +5,24 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/PayCryps/WatchdogGo/src/monitor/check"
+	"github.com/PayCryps/WatchdogGo/src/restart"
 	"github.com/PayCryps/WatchdogGo/src/utils"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/rs/zerolog"
 )
 
+var tracker = monitor.NewTracker()
+
 func MonitorDocker(logger zerolog.Logger, dockerStop chan struct{}) {
 	logger.Info().Msg("Docker Monitor thread started")
 
@@ -30,10 +38,23 @@ func MonitorDocker(logger zerolog.Logger, dockerStop chan struct{}) {
 	ticker := time.NewTicker(time.Second * time.Duration(haltDuration))
 	defer ticker.Stop()
 
+	thresholds := monitor.DefaultThresholds(logger)
+	restartDefaults := restart.Defaults(logger)
+	crashLoop := DefaultCrashLoop(logger)
+	stats := DefaultStatsSettings(logger)
+
+	// docker events trigger checks right away, polling reconciles whatever
+	// the stream missed
+	checks := make(chan struct{}, 1)
+	go WatchEvents(logger, checks, dockerStop)
+
 	for {
 		select {
 		case <-ticker.C:
-			monitorDocker(logger)
+			monitorDocker(logger, thresholds, restartDefaults, crashLoop, stats)
+
+		case <-checks:
+			monitorDocker(logger, thresholds, restartDefaults, crashLoop, stats)
 
 		case <-dockerStop:
 			logger.Info().Msg("Docker thread exiting")
@@ -42,47 +63,117 @@ func MonitorDocker(logger zerolog.Logger, dockerStop chan struct{}) {
 	}
 }
 
-func monitorDocker(logger zerolog.Logger) {
+func monitorDocker(logger zerolog.Logger, thresholds monitor.Thresholds, restartDefaults db.RestartPolicy, crashLoop CrashLoop, stats StatsSettings) {
 	dockerCli := CreateDockerClient()
 
-	// Todo: Get all the containers required to monitor and check if they are running
-	desiredConfig := container.Config{Image: "postgres:15.0-alpine"}
-	desiredName := "/postgres"
-
-	containers := []ContainerDetails{}
-	containers = append(containers, ContainerDetails{Name: desiredName, Configs: desiredConfig})
+	DiscoverContainers(dockerCli, logger)
+	containers := GetMonitoredContainers(logger)
+	rememberMonitored(containers)
+	if containers != nil {
+		// nil when the monitors failed to load
+		forgetRemovedMonitors(containers)
+	}
+	if len(containers) == 0 {
+		return
+	}
 
 	statusList := IsContainerRunning(dockerCli, containers, logger)
+	suppressions := maintenance.Load(logger)
 
-	for _, status := range statusList {
-		if !status.IsRunning {
-			if status.ContainerID != "" {
-				logger.Warn().Msgf("Restarting %s container", status.Name)
-				RestartContainer(dockerCli, status.ContainerID, logger)
-			} else {
-
-				DockerStart := os.Getenv("DOCKER_START")
-				if DockerStart == "FALSE" {
-					logger.Info().Msg("DOCKER_START is set to false, not starting container")
-					continue
-				}
+	for i, status := range statusList {
+		desired := containers[i]
 
-				// fetch the host configs from db based on the container name
-				network := "watchdog"
-				volume := mount.Mount{
-					Type:   mount.TypeVolume,
-					Source: "data",
-					Target: "/var/lib/postgresql/data",
-				}
+		oomKills := 0
+		if !status.IsRunning && status.ContainerID != "" {
+			status.Failure, oomKills = crashes.classify(desired.MonitorID, status, crashLoop)
+		}
 
-				hostConfig := container.HostConfig{
-					Mounts:      []mount.Mount{volume},
-					NetworkMode: container.NetworkMode(network),
-				}
+		// running containers failing their docker healthcheck are not healthy either
+		healthy := status.IsRunning && status.Health != types.Unhealthy
+		unhealthy := unhealthyFor(desired.MonitorID, status)
 
-				CreateAndStartContainer(dockerCli, desiredConfig, hostConfig, desiredName, logger)
-			}
+		checkThresholds := thresholds.For(desired.FailureThreshold, desired.RecoveryThreshold)
+		if confirmedFailure(desired.MonitorID) && !status.IsRunning {
+			// a die or oom event already confirmed the failure
+			checkThresholds.Failures = 1
+		}
+
+		target := check.Target{Kind: "Container", Type: db.MonitorTypeContainer, ID: desired.MonitorID, Name: status.Name, Tags: desired.Tags}
+		detail := fmt.Sprintf("state: %s, health: %s, failure: %s", status.State, status.Health, status.Failure)
+		result := check.Observe(logger, tracker, checkThresholds, suppressions, target, healthy, detail, func(state monitor.State) events.Source {
+			return containerSource(desired, status, state)
+		})
+
+		policy := restart.Effective(desired.RestartPolicy, restartDefaults)
+		if status.IsRunning && stats.Enabled {
+			checkStats(dockerCli, desired, result.Source, result.Suppressed, stats, policy, logger)
+		}
+
+		if !result.Down(logger) {
+			continue
 		}
+
+		// running containers that are down are either recovering or unhealthy, the
+		// latter are only restarted once past the grace period the monitor opted in to
+		if status.IsRunning && (status.Health != types.Unhealthy || desired.UnhealthyGrace == 0 || unhealthy < desired.UnhealthyGrace) {
+			continue
+		}
+
+		// restarting a container that keeps running out of memory only repeats the OOM kill
+		if status.Failure == FailureCrashLoop && oomKills > 0 {
+			restart.GiveUp(logger, result.Source, oomLoopReason(oomKills, crashLoop))
+			continue
+		}
+
+		// missing containers count as failed too
+		failed := status.Failure != FailureCleanExit
+		result.Remediate(logger, policy, failed, func(source *events.Source) bool {
+			return remediateContainer(dockerCli, desired, source, logger)
+		})
+	}
+}
+
+// remediateContainer restarts a stopped container or recreates a missing one.
+// It returns false when no action was taken.
+func remediateContainer(dockerCli *client.Client, desired ContainerDetails, source *events.Source, logger zerolog.Logger) bool {
+	if source.ContainerID != "" {
+		logger.Warn().Msgf("Restarting %s container", desired.Name)
+		events.Remediate(source, "restart container", func() error {
+			return RestartContainer(dockerCli, source.ContainerID, logger)
+		})
+		return true
+	}
+
+	DockerStart := os.Getenv("DOCKER_START")
+	if DockerStart == "FALSE" {
+		logger.Info().Msg("DOCKER_START is set to false, not starting container")
+		return false
+	}
+
+	events.Remediate(source, "create and start container", func() error {
+		containerID, err := CreateAndStartContainer(dockerCli, desired.Configs, desired.HostConfig, desired.Name, logger)
+		source.ContainerID = containerID
+		return err
+	})
+	return true
+}
+
+func containerSource(desired ContainerDetails, status ContainerStatus, state monitor.State) events.Source {
+	return events.Source{
+		MonitorType: db.MonitorTypeContainer,
+		MonitorID:   desired.MonitorID,
+		MonitorName: strings.TrimPrefix(desired.Name, "/"),
+		Critical:    desired.Critical,
+		State:       state,
+		Since:       tracker.Since(desired.MonitorID),
+		Status:      status.State,
+		Failure:     status.Failure,
+		ExitCode:    status.ExitCode,
+		Health:      status.Health,
+		HealthLog:   status.HealthLog,
+		Drift:       status.Drift,
+		ContainerID: status.ContainerID,
+		Time:        time.Now(),
 	}
 }
 
@@ -111,23 +202,38 @@ func IsContainerRunning(cli *client.Client, desiredContainers []ContainerDetails
 	containerStatusList := []ContainerStatus{}
 
 	for _, containerDetails := range desiredContainers {
-		found := false
-		for _, container := range containerList {
-			if container.Image == containerDetails.Configs.Image && utils.Contains(container.Names, containerDetails.Name) {
-				if container.State == "running" {
-					containerStatusList = append(containerStatusList, ContainerStatus{IsRunning: true, ContainerID: container.ID, Name: containerDetails.Name})
-				} else {
-					logger.Error().Msgf("Container %s is not running", containerDetails.Name)
-					containerStatusList = append(containerStatusList, ContainerStatus{IsRunning: false, ContainerID: container.ID, Name: containerDetails.Name})
-				}
-				found = true
-				break
-			}
-		}
-
+		container, found := findContainer(containerList, containerDetails)
 		if !found {
 			logger.Error().Msg(fmt.Sprintf("Container %s not found in docker", containerDetails.Name))
-			containerStatusList = append(containerStatusList, ContainerStatus{IsRunning: false, ContainerID: "", Name: containerDetails.Name})
+			containerStatusList = append(containerStatusList, ContainerStatus{IsRunning: false, ContainerID: "", Name: containerDetails.Name, State: "missing"})
+			continue
+		}
+
+		drift := imageDrift(container, containerDetails)
+		if drift != "" && driftReported[containerDetails.MonitorID] != drift {
+			logger.Warn().Msgf("Container %s drifted: %s", containerDetails.Name, drift)
+		}
+		driftReported[containerDetails.MonitorID] = drift
+
+		if container.State == "running" {
+			status := ContainerStatus{IsRunning: true, ContainerID: container.ID, Name: containerDetails.Name, State: container.State, Drift: drift}
+			status.Health, status.HealthLog = inspectHealth(cli, container.ID)
+			if status.Health == types.Unhealthy {
+				logger.Error().Msgf("Container %s is unhealthy", containerDetails.Name)
+			}
+			containerStatusList = append(containerStatusList, status)
+		} else {
+			logger.Error().Msgf("Container %s is not running", containerDetails.Name)
+			status := ContainerStatus{IsRunning: false, ContainerID: container.ID, Name: containerDetails.Name, State: container.State, Drift: drift}
+			if inspect, err := cli.ContainerInspect(context.Background(), container.ID); err == nil && inspect.ContainerJSONBase != nil {
+				status.RestartCount = inspect.RestartCount
+				if inspect.State != nil {
+					status.ExitCode = inspect.State.ExitCode
+					status.OOMKilled = inspect.State.OOMKilled
+					status.FinishedAt, _ = time.Parse(time.RFC3339Nano, inspect.State.FinishedAt)
+				}
+			}
+			containerStatusList = append(containerStatusList, status)
 		}
 	}
 
@@ -151,35 +257,48 @@ func RemoveContainer(cli *client.Client, containerID string, logger zerolog.Logg
 func FindAndRemoveContainer(cli *client.Client, desiredConfig container.Config, desiredName string, logger zerolog.Logger) {
 	containerList := GetDockerContainers(cli, logger)
 
+	monitorID := desiredConfig.Labels[MonitorIDLabel]
 	for _, container := range containerList {
-		if container.Image == desiredConfig.Image && utils.Contains(container.Names, desiredName) {
+		// the name is taken by any container using it, whatever its image
+		if (monitorID != "" && container.Labels[MonitorIDLabel] == monitorID) || utils.Contains(container.Names, desiredName) {
 			RemoveContainer(cli, container.ID, logger)
 		}
 	}
 }
 
-func CreateAndStartContainer(cli *client.Client, desiredConfig container.Config, hostConfig container.HostConfig, desiredContainerName string, logger zerolog.Logger) {
+func CreateAndStartContainer(cli *client.Client, desiredConfig container.Config, hostConfig container.HostConfig, desiredContainerName string, logger zerolog.Logger) (string, error) {
 	ctx := context.Background()
 
+	if err := EnsureImage(cli, desiredConfig.Image, logger); err != nil {
+		logger.Error().Err(err).Msgf("Failed to pull the image of container %s", desiredContainerName)
+		return "", err
+	}
+
 	FindAndRemoveContainer(cli, desiredConfig, desiredContainerName, logger)
 
 	containerResp, err := cli.ContainerCreate(ctx, &desiredConfig, &hostConfig, nil, nil, desiredContainerName)
 	if err != nil {
 		logger.Error().Msg(fmt.Sprintf("Failed to create container %s", desiredContainerName))
+		return "", err
 	}
 
 	if err := cli.ContainerStart(ctx, containerResp.ID, container.StartOptions{}); err != nil {
 		logger.Error().Msg(fmt.Sprintf("Failed to start container %s", desiredContainerName))
+		return "", err
 	}
 
 	logger.Info().Msg(fmt.Sprintf("Container created and started: %s", containerResp.ID))
+
+	return containerResp.ID, nil
 }
 
-func RestartContainer(cli *client.Client, containerID string, logger zerolog.Logger) {
+func RestartContainer(cli *client.Client, containerID string, logger zerolog.Logger) error {
 	ctx := context.Background()
 
 	err := cli.ContainerRestart(ctx, containerID, container.StopOptions{})
 	if err != nil {
 		logger.Error().Msg(fmt.Sprintf("Failed to restart container %s", containerID))
 	}
+
+	return err
 }
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/rs/zerolog"
+)
+
+// Failure classes of a stopped container
+const (
+	FailureCleanExit = "clean-exit"
+	FailureCrash     = "crash"
+	FailureOOMKilled = "oom-killed"
+	FailureCrashLoop = "crash-loop"
+)
+
+// CrashLoop decides when repeated crashes of a container are a crash loop.
+type CrashLoop struct {
+	// Count is the number of crashes within Window that make a crash loop
+	Count  int
+	Window time.Duration
+}
+
+// DefaultCrashLoop reads CRASH_LOOP_COUNT and CRASH_LOOP_WINDOW (seconds)
+// from the environment.
+func DefaultCrashLoop(logger zerolog.Logger) CrashLoop {
+	return CrashLoop{
+		Count:  utils.EnvInt(logger, "CRASH_LOOP_COUNT", 3),
+		Window: time.Duration(utils.EnvInt(logger, "CRASH_LOOP_WINDOW", 600)) * time.Second,
+	}
+}
+
+type crashRecord struct {
+	finishedAt   time.Time
+	restartCount int
+	// crashes are the times the container stopped with an error
+	crashes []time.Time
+	// oomKills counts the crashes within the window that were OOM kills
+	oomKills []time.Time
+}
+
+// crashHistory remembers the recent crashes of every container, told apart by
+// their FinishedAt time and docker's restart count.
+type crashHistory struct {
+	mu      sync.Mutex
+	records map[string]*crashRecord
+}
+
+var crashes = &crashHistory{records: make(map[string]*crashRecord)}
+
+// classify records the crash in status, if it was not seen before, and
+// returns the failure class of the stopped container together with the
+// number of OOM kills within the crash loop window.
+func (h *crashHistory) classify(monitorID string, status ContainerStatus, loop CrashLoop) (string, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	record, ok := h.records[monitorID]
+	if !ok {
+		record = &crashRecord{}
+		h.records[monitorID] = record
+	}
+
+	now := time.Now()
+	crashed := status.OOMKilled || status.ExitCode != 0 || status.State == "restarting"
+	// a new crash either has a new FinishedAt or bumped docker's restart count
+	if crashed && (!status.FinishedAt.Equal(record.finishedAt) || status.RestartCount > record.restartCount) {
+		record.crashes = append(record.crashes, now)
+		if status.OOMKilled {
+			record.oomKills = append(record.oomKills, now)
+		}
+	}
+	record.finishedAt = status.FinishedAt
+	record.restartCount = status.RestartCount
+	record.crashes = recentCrashes(record.crashes, now, loop.Window)
+	record.oomKills = recentCrashes(record.oomKills, now, loop.Window)
+
+	switch {
+	case status.State == "restarting" || (loop.Count > 0 && len(record.crashes) >= loop.Count):
+		return FailureCrashLoop, len(record.oomKills)
+	case status.OOMKilled:
+		return FailureOOMKilled, len(record.oomKills)
+	case crashed || status.State != "exited":
+		// dead and created containers count as crashed too
+		return FailureCrash, len(record.oomKills)
+	}
+
+	return FailureCleanExit, len(record.oomKills)
+}
+
+func recentCrashes(crashes []time.Time, now time.Time, window time.Duration) []time.Time {
+	recent := []time.Time{}
+	for _, crash := range crashes {
+		if now.Sub(crash) < window {
+			recent = append(recent, crash)
+		}
+	}
+
+	return recent
+}
+
+// oomLoopReason explains why an OOM-killed crash loop is not restarted.
+func oomLoopReason(oomKills int, loop CrashLoop) string {
+	return fmt.Sprintf("OOM-killed %d times within %s, restarting will not help", oomKills, loop.Window)
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	finished := time.Now().Add(-time.Minute)
+	loop := CrashLoop{Count: 3, Window: 10 * time.Minute}
+
+	tests := []struct {
+		name    string
+		status  ContainerStatus
+		failure string
+		ooms    int
+	}{
+		{"clean exit", ContainerStatus{State: "exited", ExitCode: 0, FinishedAt: finished}, FailureCleanExit, 0},
+		{"error exit", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: finished}, FailureCrash, 0},
+		{"killed", ContainerStatus{State: "exited", ExitCode: 137, FinishedAt: finished}, FailureCrash, 0},
+		{"oom kill", ContainerStatus{State: "exited", ExitCode: 137, OOMKilled: true, FinishedAt: finished}, FailureOOMKilled, 1},
+		{"restarting", ContainerStatus{State: "restarting", FinishedAt: finished}, FailureCrashLoop, 0},
+		{"dead", ContainerStatus{State: "dead", FinishedAt: finished}, FailureCrash, 0},
+		{"created", ContainerStatus{State: "created"}, FailureCrash, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			history := &crashHistory{records: map[string]*crashRecord{}}
+			failure, ooms := history.classify("web", test.status, loop)
+			if failure != test.failure || ooms != test.ooms {
+				t.Fatalf("classify() = %s, %d, want %s, %d", failure, ooms, test.failure, test.ooms)
+			}
+		})
+	}
+}
+
+func TestClassifyCrashLoop(t *testing.T) {
+	history := &crashHistory{records: map[string]*crashRecord{}}
+	loop := CrashLoop{Count: 3, Window: 10 * time.Minute}
+	start := time.Now().Add(-time.Hour)
+
+	steps := []struct {
+		name    string
+		status  ContainerStatus
+		failure string
+		ooms    int
+	}{
+		{"first crash", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start}, FailureCrash, 0},
+		// the same stop is seen on every check until the container runs again
+		{"same crash checked again", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start}, FailureCrash, 0},
+		{"oom kill", ContainerStatus{State: "exited", ExitCode: 137, OOMKilled: true, FinishedAt: start.Add(time.Minute)}, FailureOOMKilled, 1},
+		{"third crash", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start.Add(2 * time.Minute)}, FailureCrashLoop, 1},
+		{"clean exit within the window", ContainerStatus{State: "exited", FinishedAt: start.Add(3 * time.Minute)}, FailureCrashLoop, 1},
+	}
+
+	for _, step := range steps {
+		failure, ooms := history.classify("web", step.status, loop)
+		if failure != step.failure || ooms != step.ooms {
+			t.Fatalf("%s: classify() = %s, %d, want %s, %d", step.name, failure, ooms, step.failure, step.ooms)
+		}
+	}
+
+	// docker restarting the container itself keeps FinishedAt moving, a bumped
+	// restart count is a crash too
+	other := &crashHistory{records: map[string]*crashRecord{}}
+	for count := 1; count <= 3; count++ {
+		failure, _ := other.classify("api", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start, RestartCount: count}, loop)
+		if want := map[bool]string{true: FailureCrashLoop, false: FailureCrash}[count == 3]; failure != want {
+			t.Fatalf("restart %d: classify() = %s, want %s", count, failure, want)
+		}
+	}
+
+	// crashes out of the window are forgotten
+	history.records["web"].crashes = []time.Time{time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)}
+	history.records["web"].oomKills = []time.Time{time.Now().Add(-time.Hour)}
+	failure, ooms := history.classify("web", ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start.Add(4 * time.Minute)}, loop)
+	if failure != FailureCrash || ooms != 0 {
+		t.Fatalf("after the window: classify() = %s, %d, want %s, 0", failure, ooms, FailureCrash)
+	}
+
+	// a zero count never makes a crash loop
+	disabled := &crashHistory{records: map[string]*crashRecord{}}
+	for i := 0; i < 5; i++ {
+		status := ContainerStatus{State: "exited", ExitCode: 1, FinishedAt: start.Add(time.Duration(i) * time.Minute)}
+		if failure, _ := disabled.classify("web", status, CrashLoop{Window: time.Hour}); failure != FailureCrash {
+			t.Fatalf("crash %d with loop detection disabled: %s", i, failure)
+		}
+	}
+}
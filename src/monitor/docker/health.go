@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// healthLogLimit caps the healthcheck output kept for alerts
+const healthLogLimit = 500
+
+// inspectHealth returns the healthcheck status of a container and, when it is
+// unhealthy, the output of its latest check. The status is empty for
+// containers without a healthcheck.
+func inspectHealth(cli *client.Client, containerID string) (string, string) {
+	inspect, err := cli.ContainerInspect(context.Background(), containerID)
+	if err != nil || inspect.ContainerJSONBase == nil || inspect.State == nil || inspect.State.Health == nil {
+		return "", ""
+	}
+
+	health := inspect.State.Health
+	if health.Status != types.Unhealthy || len(health.Log) == 0 {
+		return health.Status, ""
+	}
+
+	output := strings.TrimSpace(health.Log[len(health.Log)-1].Output)
+	if len(output) > healthLogLimit {
+		output = output[:healthLogLimit] + "..."
+	}
+
+	return health.Status, output
+}
+
+// unhealthySince remembers when running containers started failing their
+// healthcheck. It is only used by the check loop.
+var unhealthySince = map[string]time.Time{}
+
+// unhealthyFor returns how long the container of a monitor has been unhealthy.
+func unhealthyFor(monitorID string, status ContainerStatus) time.Duration {
+	if status.Health != types.Unhealthy {
+		delete(unhealthySince, monitorID)
+		return 0
+	}
+
+	since, ok := unhealthySince[monitorID]
+	if !ok {
+		since = time.Now()
+		unhealthySince[monitorID] = since
+	}
+
+	return time.Since(since)
+}
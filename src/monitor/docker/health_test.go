@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestUnhealthyFor(t *testing.T) {
+	t.Cleanup(func() { delete(unhealthySince, "web") })
+
+	steps := []struct {
+		name   string
+		health string
+		// since moves the start of the unhealthy streak back before the check
+		since time.Duration
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"no healthcheck", "", 0, 0, 0},
+		{"starting", types.Starting, 0, 0, 0},
+		{"first unhealthy check", types.Unhealthy, 0, 0, time.Second},
+		{"still unhealthy", types.Unhealthy, 2 * time.Minute, 2 * time.Minute, 2*time.Minute + time.Second},
+		{"healthy again", types.Healthy, 0, 0, 0},
+		{"unhealthy after recovering", types.Unhealthy, 0, 0, time.Second},
+	}
+
+	for _, step := range steps {
+		if since, ok := unhealthySince["web"]; ok && step.since > 0 {
+			unhealthySince["web"] = since.Add(-step.since)
+		}
+
+		got := unhealthyFor("web", ContainerStatus{IsRunning: true, Health: step.health})
+		if got < step.min || got > step.max {
+			t.Fatalf("%s: unhealthyFor() = %s, want between %s and %s", step.name, got, step.min, step.max)
+		}
+
+		if _, ok := unhealthySince["web"]; ok != (step.health == types.Unhealthy) {
+			t.Fatalf("%s: remembered unhealthy since = %v", step.name, ok)
+		}
+	}
+}
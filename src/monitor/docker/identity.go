@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/docker/docker/api/types"
+)
+
+// MonitorIDLabel is set on every container the watchdog creates, so the
+// container is still found after its image or name changed.
+const MonitorIDLabel = "watchdog.monitor-id"
+
+// driftReported is the last drift logged per monitor, so it is logged once.
+var driftReported = map[string]string{}
+
+// findContainer returns the container of a monitor, matched by its identity
+// label first and by name for containers created outside the watchdog.
+func findContainer(containers []types.Container, desired ContainerDetails) (types.Container, bool) {
+	for _, container := range containers {
+		if desired.MonitorID != "" && container.Labels[MonitorIDLabel] == desired.MonitorID {
+			return container, true
+		}
+	}
+
+	for _, container := range containers {
+		if utils.Contains(container.Names, desired.Name) {
+			return container, true
+		}
+	}
+
+	return types.Container{}, false
+}
+
+// imageDrift describes how the image of a container differs from the one of
+// its monitor, or returns an empty string when they match. Docker reports
+// the image id instead of the reference once the tag moved to another image.
+func imageDrift(container types.Container, desired ContainerDetails) string {
+	if sameImage(container.Image, desired.Configs.Image) || container.ImageID == desired.Configs.Image {
+		return ""
+	}
+
+	return fmt.Sprintf("running image %s, monitor expects %s", container.Image, desired.Configs.Image)
+}
+
+// sameImage compares image references, an untagged reference means latest.
+func sameImage(actual string, expected string) bool {
+	return withTag(actual) == withTag(expected)
+}
+
+func withTag(image string) string {
+	if strings.Contains(image, "@") {
+		return image
+	}
+	if strings.LastIndex(image, ":") <= strings.LastIndex(image, "/") {
+		return image + ":latest"
+	}
+
+	return image
+}
@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestFindContainer(t *testing.T) {
+	containers := []types.Container{
+		{ID: "renamed", Names: []string{"/web-old"}, Labels: map[string]string{MonitorIDLabel: "monitor-1"}},
+		{ID: "named", Names: []string{"/web"}},
+		{ID: "other", Names: []string{"/api"}, Labels: map[string]string{MonitorIDLabel: "monitor-2"}},
+	}
+
+	tests := []struct {
+		name    string
+		desired ContainerDetails
+		id      string
+		found   bool
+	}{
+		{"label wins over name", ContainerDetails{MonitorID: "monitor-1", Name: "/web"}, "renamed", true},
+		{"name without label", ContainerDetails{MonitorID: "monitor-3", Name: "/web"}, "named", true},
+		{"no monitor id", ContainerDetails{Name: "/api"}, "other", true},
+		{"name needs the leading slash", ContainerDetails{Name: "web"}, "", false},
+		{"missing", ContainerDetails{MonitorID: "monitor-4", Name: "/db"}, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, found := findContainer(containers, test.desired)
+			if found != test.found || got.ID != test.id {
+				t.Fatalf("findContainer() = %q, %v, want %q, %v", got.ID, found, test.id, test.found)
+			}
+		})
+	}
+}
+
+func TestImageDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		imageID  string
+		expected string
+		drift    bool
+	}{
+		{"same reference", "nginx:1.27", "sha256:a", "nginx:1.27", false},
+		{"untagged means latest", "nginx", "sha256:a", "nginx:latest", false},
+		{"latest means untagged", "nginx:latest", "sha256:a", "nginx", false},
+		{"other tag", "nginx:1.26", "sha256:a", "nginx:1.27", true},
+		{"registry port is not a tag", "registry:5000/app", "sha256:a", "registry:5000/app:latest", false},
+		{"registry port with other tag", "registry:5000/app:v1", "sha256:a", "registry:5000/app", true},
+		{"same digest", "nginx@sha256:b", "sha256:b", "nginx@sha256:b", false},
+		{"other digest", "nginx@sha256:b", "sha256:b", "nginx@sha256:c", true},
+		{"tag moved, docker reports the id", "sha256:a", "sha256:a", "sha256:a", false},
+		{"tag moved to another image", "sha256:a", "sha256:a", "nginx:1.27", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			running := types.Container{Image: test.image, ImageID: test.imageID}
+			desired := ContainerDetails{Configs: container.Config{Image: test.expected}}
+
+			drift := imageDrift(running, desired)
+			if (drift != "") != test.drift {
+				t.Fatalf("imageDrift(%s, %s) = %q, want drift %v", test.image, test.expected, drift, test.drift)
+			}
+		})
+	}
+}
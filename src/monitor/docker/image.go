@@ -0,0 +1,148 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// dockerHub is the registry of images without a registry in their name
+const dockerHub = "docker.io"
+
+// NormalizeRegistry turns a registry address into the form stored with its
+// credentials, e.g. "https://index.docker.io/v1/" into "docker.io".
+func NormalizeRegistry(address string) string {
+	address = strings.TrimSpace(address)
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address = strings.ToLower(strings.SplitN(address, "/", 2)[0])
+
+	switch address {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHub
+	}
+
+	return address
+}
+
+// registryOf returns the registry an image is pulled from.
+func registryOf(imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", imageName, err)
+	}
+
+	return NormalizeRegistry(reference.Domain(named)), nil
+}
+
+// registryAuth encodes the stored credentials of the registry of an image,
+// it is empty for registries without credentials.
+func registryAuth(imageName string) (string, error) {
+	server, err := registryOf(imageName)
+	if err != nil {
+		return "", err
+	}
+
+	var credential db.RegistryCredential
+	if err := db.DB.First(&credential, "registry = ?", server).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	password, err := secrets.Decrypt(credential.PasswordEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("credentials of %s: %w", server, err)
+	}
+
+	address := server
+	if server == dockerHub {
+		address = "https://index.docker.io/v1/"
+	}
+
+	return registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      credential.Username,
+		Password:      password,
+		ServerAddress: address,
+	})
+}
+
+// pullMessage is a line of the progress stream of an image pull.
+type pullMessage struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	ErrorDetail struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// EnsureImage pulls an image that is not on the host yet, with the stored
+// credentials of its registry.
+func EnsureImage(cli *client.Client, imageName string, logger zerolog.Logger) error {
+	ctx := context.Background()
+
+	_, _, err := cli.ImageInspectWithRaw(ctx, imageName)
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return err
+	}
+
+	auth, err := registryAuth(imageName)
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+
+	logger.Info().Msgf("Pulling image %s", imageName)
+	progress, err := cli.ImagePull(ctx, imageName, image.PullOptions{RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+	defer progress.Close()
+
+	// the pull only finished once the stream ends, errors are reported in it
+	layers := map[string]string{}
+	decoder := json.NewDecoder(progress)
+	for {
+		var message pullMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+		}
+
+		if message.Error != "" {
+			if message.ErrorDetail.Message != "" {
+				message.Error = message.ErrorDetail.Message
+			}
+			return fmt.Errorf("failed to pull image %s: %s", imageName, message.Error)
+		}
+
+		if message.ID == "" || strings.HasPrefix(message.Status, "Pulling from") {
+			logger.Info().Msgf("Pulling image %s: %s", imageName, message.Status)
+		} else if layers[message.ID] != message.Status {
+			// every layer reports its progress many times, only log its steps
+			layers[message.ID] = message.Status
+			logger.Debug().Msgf("Pulling image %s: layer %s %s", imageName, message.ID, message.Status)
+		}
+	}
+
+	logger.Info().Msgf("Pulled image %s", imageName)
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package docker
+
+import "testing"
+
+func TestNormalizeRegistry(t *testing.T) {
+	tests := map[string]string{
+		"docker.io":                    "docker.io",
+		"https://index.docker.io/v1/":  "docker.io",
+		" registry-1.docker.io ":       "docker.io",
+		"  GHCR.io\n":                  "ghcr.io",
+		"http://registry.local:5000/x": "registry.local:5000",
+		"   ":                          "",
+	}
+
+	for address, want := range tests {
+		if got := NormalizeRegistry(address); got != want {
+			t.Errorf("NormalizeRegistry(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
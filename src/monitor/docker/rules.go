@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/restart"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+type ruleState struct {
+	rule db.StatRule
+	// breachedSince is when the reading first went above the rule, zero while below
+	breachedSince time.Time
+	firing        bool
+	// restarted is set once a firing rule restarted the container
+	restarted bool
+}
+
+// ruleStates are the rule states of every monitor, keyed by ruleKey so an
+// edited rule starts over. It is only used by the check loop.
+var ruleStates = map[string]map[string]*ruleState{}
+
+// RuleString describes a rule, e.g. "memory_percent > 90 for 5m".
+func RuleString(rule db.StatRule) string {
+	return fmt.Sprintf("%s > %g for %dm", rule.Metric, rule.Above, rule.DurationMinutes)
+}
+
+func ruleKey(rule db.StatRule) string {
+	return fmt.Sprintf("%s restart=%t", RuleString(rule), rule.Restart)
+}
+
+// checkStats samples a running container, stores the sample and applies the
+// stat rules of its monitor. Fired rules with Restart set restart the
+// container within its restart policy, unless the monitor is suppressed.
+func checkStats(cli *client.Client, desired ContainerDetails, source events.Source, suppressed string, settings StatsSettings, policy db.RestartPolicy, logger zerolog.Logger) {
+	sample, err := ReadStats(cli, desired.MonitorID, source.ContainerID)
+	if err != nil {
+		logger.Debug().Err(err).Msgf("Failed to read stats of container %s", desired.Name)
+		return
+	}
+	storeStats(sample, settings, logger)
+	applyRules(cli, desired, source, sample, suppressed, policy, logger)
+}
+
+// applyRules moves the rule states of a monitor on with a new sample. Rules
+// fire once the reading stayed above them for their duration, and clear as
+// soon as it is back below.
+func applyRules(cli *client.Client, desired ContainerDetails, source events.Source, sample db.ContainerStats, suppressed string, policy db.RestartPolicy, logger zerolog.Logger) {
+	previous := ruleStates[desired.MonitorID]
+	states := map[string]*ruleState{}
+	for _, rule := range desired.StatRules {
+		key := ruleKey(rule)
+		if state, ok := previous[key]; ok {
+			states[key] = state
+		} else {
+			states[key] = &ruleState{rule: rule}
+		}
+	}
+	ruleStates[desired.MonitorID] = states
+
+	// rules removed while firing are cleared so their incident can resolve
+	for key, state := range previous {
+		if _, kept := states[key]; !kept && state.firing {
+			source.Time = time.Now()
+			events.Publish(events.ThresholdCleared{Source: source, Rule: RuleString(state.rule), Value: StatValue(sample, state.rule.Metric), Firing: firingRules(states), Suppressed: suppressed})
+		}
+	}
+
+	for _, rule := range desired.StatRules {
+		state := states[ruleKey(rule)]
+		value := StatValue(sample, rule.Metric)
+
+		if value <= rule.Above {
+			state.breachedSince = time.Time{}
+			state.restarted = false
+			if state.firing {
+				state.firing = false
+				logger.Info().Msgf("Container %s is back below %s (%g)", desired.Name, RuleString(rule), value)
+				source.Time = time.Now()
+				events.Publish(events.ThresholdCleared{Source: source, Rule: RuleString(rule), Value: value, Firing: firingRules(states), Suppressed: suppressed})
+			}
+			continue
+		}
+
+		if state.breachedSince.IsZero() {
+			state.breachedSince = sample.At
+		}
+		if !state.firing && sample.At.Sub(state.breachedSince) < time.Duration(rule.DurationMinutes)*time.Minute {
+			continue
+		}
+
+		if !state.firing {
+			state.firing = true
+			logger.Warn().Msgf("Container %s crossed %s (%g)", desired.Name, RuleString(rule), value)
+			source.Time = time.Now()
+			events.Publish(events.ThresholdBreached{Source: source, Rule: RuleString(rule), Value: value, Suppressed: suppressed})
+		}
+
+		// restarts denied by the policy, e.g. while backing off, are retried as
+		// long as the rule stays breached
+		if !rule.Restart || state.restarted || suppressed != "" || !restart.Allow(logger, source, policy, true) {
+			continue
+		}
+		state.restarted = true
+		events.Remediate(&source, "restart container ("+RuleString(rule)+")", func() error {
+			return RestartContainer(cli, source.ContainerID, logger)
+		})
+		restart.Record(logger, db.MonitorTypeContainer, desired.MonitorID, policy)
+	}
+}
+
+func firingRules(states map[string]*ruleState) int {
+	firing := 0
+	for _, state := range states {
+		if state.firing {
+			firing++
+		}
+	}
+
+	return firing
+}
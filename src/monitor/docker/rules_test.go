@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/rs/zerolog"
+)
+
+// thresholdEvents returns the threshold events published until the bus has
+// been quiet for a moment.
+func thresholdEvents(published <-chan events.Event) []events.Event {
+	var received []events.Event
+	for {
+		select {
+		case event := <-published:
+			switch event.(type) {
+			case events.ThresholdBreached, events.ThresholdCleared:
+				received = append(received, event)
+			}
+		case <-time.After(50 * time.Millisecond):
+			return received
+		}
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	t.Cleanup(func() { delete(ruleStates, "web") })
+
+	published, unsubscribe := events.Channel(t.Name())
+	defer unsubscribe()
+
+	memory := db.StatRule{Metric: db.StatMemoryPercent, Above: 90, DurationMinutes: 5}
+	cpu := db.StatRule{Metric: db.StatCPUPercent, Above: 80}
+	desired := ContainerDetails{MonitorID: "web", Name: "/web", StatRules: []db.StatRule{memory, cpu}}
+	source := events.Source{MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web"}
+	start := time.Now()
+
+	steps := []struct {
+		name    string
+		at      time.Duration
+		memory  float64
+		cpu     float64
+		breach  string
+		cleared string
+		firing  int
+	}{
+		{"below both rules", 0, 50, 10, "", "", 0},
+		{"memory above, not for long enough", time.Minute, 95, 10, "", "", 0},
+		{"memory still above", 4 * time.Minute, 95, 10, "", "", 0},
+		{"memory above for the duration", 6 * time.Minute, 95, 10, RuleString(memory), "", 0},
+		{"still firing is not sent again", 7 * time.Minute, 95, 10, "", "", 0},
+		{"cpu rule without duration fires at once", 8 * time.Minute, 95, 99, RuleString(cpu), "", 0},
+		{"memory back below", 9 * time.Minute, 50, 99, "", RuleString(memory), 1},
+		{"memory above again starts over", 10 * time.Minute, 95, 99, "", "", 0},
+		{"cpu back below", 11 * time.Minute, 95, 10, "", RuleString(cpu), 0},
+	}
+
+	for _, step := range steps {
+		sample := db.ContainerStats{MonitorID: "web", At: start.Add(step.at), MemoryPercent: step.memory, CPUPercent: step.cpu}
+		applyRules(nil, desired, source, sample, "", db.RestartPolicy{}, zerolog.Nop())
+
+		received := thresholdEvents(published)
+		want := 0
+		if step.breach != "" || step.cleared != "" {
+			want = 1
+		}
+		if len(received) != want {
+			t.Fatalf("%s: published %+v", step.name, received)
+		}
+
+		for _, event := range received {
+			switch event := event.(type) {
+			case events.ThresholdBreached:
+				if event.Rule != step.breach {
+					t.Fatalf("%s: breached %s, want %q", step.name, event.Rule, step.breach)
+				}
+			case events.ThresholdCleared:
+				if event.Rule != step.cleared || event.Firing != step.firing {
+					t.Fatalf("%s: cleared %s with %d firing, want %q with %d", step.name, event.Rule, event.Firing, step.cleared, step.firing)
+				}
+			}
+		}
+	}
+
+	// a rule removed while firing is cleared so its incident can resolve
+	applyRules(nil, desired, source, db.ContainerStats{MonitorID: "web", At: start.Add(20 * time.Minute), MemoryPercent: 95}, "", db.RestartPolicy{}, zerolog.Nop())
+	thresholdEvents(published)
+
+	desired.StatRules = []db.StatRule{cpu}
+	applyRules(nil, desired, source, db.ContainerStats{MonitorID: "web", At: start.Add(21 * time.Minute), MemoryPercent: 95}, "", db.RestartPolicy{}, zerolog.Nop())
+	received := thresholdEvents(published)
+	if len(received) != 1 {
+		t.Fatalf("removing a firing rule published %+v", received)
+	}
+	if cleared, ok := received[0].(events.ThresholdCleared); !ok || cleared.Rule != RuleString(memory) || cleared.Firing != 0 {
+		t.Fatalf("removing a firing rule published %+v", received[0])
+	}
+}
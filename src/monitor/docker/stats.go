@@ -0,0 +1,168 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+// StatsSettings decide how often samples are stored and for how long.
+type StatsSettings struct {
+	Enabled   bool
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+// DefaultStatsSettings reads DOCKER_STATS, STATS_SAMPLE_INTERVAL (seconds)
+// and STATS_RETENTION_HOURS from the environment. Rules are evaluated on
+// every check, samples are only stored once per interval.
+func DefaultStatsSettings(logger zerolog.Logger) StatsSettings {
+	return StatsSettings{
+		Enabled:   os.Getenv("DOCKER_STATS") != "FALSE",
+		Interval:  time.Duration(utils.EnvInt(logger, "STATS_SAMPLE_INTERVAL", 60)) * time.Second,
+		Retention: time.Duration(utils.EnvInt(logger, "STATS_RETENTION_HOURS", 24)) * time.Hour,
+	}
+}
+
+type cpuSample struct {
+	container uint64
+	system    uint64
+}
+
+// statsHistory keeps what the check loop needs between two samples.
+type statsHistory struct {
+	cpu    map[string]cpuSample
+	stored map[string]time.Time
+	pruned time.Time
+}
+
+var history = statsHistory{cpu: map[string]cpuSample{}, stored: map[string]time.Time{}}
+
+// ReadStats samples the resource usage of a running container. One shot
+// stats have no previous cpu reading, so the cpu usage is computed against
+// the sample of the last check and is 0 on the first one.
+func ReadStats(cli *client.Client, monitorID string, containerID string) (db.ContainerStats, error) {
+	reader, err := cli.ContainerStatsOneShot(context.Background(), containerID)
+	if err != nil {
+		return db.ContainerStats{}, err
+	}
+	defer reader.Body.Close()
+
+	var response container.StatsResponse
+	if err := json.NewDecoder(reader.Body).Decode(&response); err != nil {
+		return db.ContainerStats{}, err
+	}
+
+	return sampleFromResponse(monitorID, response, time.Now()), nil
+}
+
+// sampleFromResponse turns the stats docker reported for a container into a
+// sample, remembering its cpu reading for the next one.
+func sampleFromResponse(monitorID string, response container.StatsResponse, at time.Time) db.ContainerStats {
+	sample := db.ContainerStats{MonitorID: monitorID, At: at}
+
+	cpu := cpuSample{container: response.CPUStats.CPUUsage.TotalUsage, system: response.CPUStats.SystemUsage}
+	if previous, ok := history.cpu[monitorID]; ok && cpu.system > previous.system && cpu.container >= previous.container {
+		cpus := float64(response.CPUStats.OnlineCPUs)
+		if cpus == 0 {
+			cpus = float64(len(response.CPUStats.CPUUsage.PercpuUsage))
+		}
+		sample.CPUPercent = float64(cpu.container-previous.container) / float64(cpu.system-previous.system) * cpus * 100
+	}
+	history.cpu[monitorID] = cpu
+
+	// page cache can be reclaimed, docker stats leaves it out as well
+	usage := response.MemoryStats.Usage
+	if cache, ok := response.MemoryStats.Stats["inactive_file"]; ok && cache < usage {
+		usage -= cache
+	} else if cache, ok := response.MemoryStats.Stats["total_inactive_file"]; ok && cache < usage {
+		usage -= cache
+	}
+	sample.MemoryUsage = int64(usage)
+	sample.MemoryLimit = int64(response.MemoryStats.Limit)
+	if response.MemoryStats.Limit > 0 {
+		sample.MemoryPercent = float64(usage) / float64(response.MemoryStats.Limit) * 100
+	}
+
+	for _, network := range response.Networks {
+		sample.NetworkRx += int64(network.RxBytes)
+		sample.NetworkTx += int64(network.TxBytes)
+	}
+
+	for _, entry := range response.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			sample.BlockRead += int64(entry.Value)
+		case "write":
+			sample.BlockWrite += int64(entry.Value)
+		}
+	}
+
+	return sample
+}
+
+// forgetRemovedMonitors drops the stats and rule states of monitors that are
+// no longer monitored, e.g. deleted or disabled ones.
+func forgetRemovedMonitors(containers []ContainerDetails) {
+	kept := map[string]bool{}
+	for _, container := range containers {
+		kept[container.MonitorID] = true
+	}
+
+	for monitorID := range history.cpu {
+		if !kept[monitorID] {
+			delete(history.cpu, monitorID)
+		}
+	}
+	for monitorID := range history.stored {
+		if !kept[monitorID] {
+			delete(history.stored, monitorID)
+		}
+	}
+	for monitorID := range ruleStates {
+		if !kept[monitorID] {
+			delete(ruleStates, monitorID)
+		}
+	}
+}
+
+// StatValue returns metric of a sample, see db.StatCPUPercent and the like.
+func StatValue(sample db.ContainerStats, metric string) float64 {
+	switch metric {
+	case db.StatCPUPercent:
+		return sample.CPUPercent
+	case db.StatMemoryPercent:
+		return sample.MemoryPercent
+	case db.StatMemoryBytes:
+		return float64(sample.MemoryUsage)
+	}
+
+	return 0
+}
+
+// storeStats saves a sample once per interval and prunes the samples older
+// than the retention once an hour.
+func storeStats(sample db.ContainerStats, settings StatsSettings, logger zerolog.Logger) {
+	if time.Since(history.stored[sample.MonitorID]) >= settings.Interval {
+		if err := db.DB.Create(&sample).Error; err != nil {
+			logger.Error().Err(err).Msgf("Failed to store stats of monitor %s", sample.MonitorID)
+			return
+		}
+		history.stored[sample.MonitorID] = sample.At
+	}
+
+	if time.Since(history.pruned) >= time.Hour {
+		history.pruned = time.Now()
+		if err := db.DB.Where("at < ?", time.Now().Add(-settings.Retention)).Delete(&db.ContainerStats{}).Error; err != nil {
+			logger.Error().Err(err).Msg("Failed to prune container stats")
+		}
+	}
+}
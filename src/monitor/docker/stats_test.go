@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types/container"
+)
+
+func cpuResponse(total uint64, system uint64, cpus uint32) container.StatsResponse {
+	var response container.StatsResponse
+	response.CPUStats.CPUUsage.TotalUsage = total
+	response.CPUStats.SystemUsage = system
+	response.CPUStats.OnlineCPUs = cpus
+	return response
+}
+
+func TestSampleCPUPercent(t *testing.T) {
+	t.Cleanup(func() { delete(history.cpu, "web") })
+
+	steps := []struct {
+		name     string
+		response container.StatsResponse
+		percent  float64
+	}{
+		{"first sample has no previous reading", cpuResponse(1000, 10000, 2), 0},
+		{"half of one of two cpus", cpuResponse(1500, 12000, 2), 50},
+		{"all of both cpus", cpuResponse(3500, 14000, 2), 200},
+		{"counter reset after a restart", cpuResponse(100, 16000, 2), 0},
+		{"no system time passed", cpuResponse(200, 16000, 2), 0},
+		{"per cpu usage without online cpus", func() container.StatsResponse {
+			response := cpuResponse(1200, 20000, 0)
+			response.CPUStats.CPUUsage.PercpuUsage = []uint64{500, 500, 0, 0}
+			return response
+		}(), 100},
+	}
+
+	for _, step := range steps {
+		sample := sampleFromResponse("web", step.response, time.Now())
+		if math.Abs(sample.CPUPercent-step.percent) > 0.001 {
+			t.Fatalf("%s: cpu percent = %g, want %g", step.name, sample.CPUPercent, step.percent)
+		}
+	}
+}
+
+func TestSampleMemory(t *testing.T) {
+	t.Cleanup(func() { delete(history.cpu, "web") })
+
+	tests := []struct {
+		name    string
+		usage   uint64
+		stats   map[string]uint64
+		limit   uint64
+		bytes   int64
+		percent float64
+	}{
+		{"cgroup v2 page cache", 1000, map[string]uint64{"inactive_file": 200}, 4000, 800, 20},
+		{"cgroup v1 page cache", 1000, map[string]uint64{"total_inactive_file": 600}, 4000, 400, 10},
+		{"no page cache reported", 1000, nil, 4000, 1000, 25},
+		{"page cache above usage is ignored", 1000, map[string]uint64{"inactive_file": 2000}, 4000, 1000, 25},
+		{"no limit", 1000, map[string]uint64{"inactive_file": 200}, 0, 800, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response container.StatsResponse
+			response.MemoryStats = container.MemoryStats{Usage: test.usage, Stats: test.stats, Limit: test.limit}
+
+			sample := sampleFromResponse("web", response, time.Now())
+			if sample.MemoryUsage != test.bytes || math.Abs(sample.MemoryPercent-test.percent) > 0.001 {
+				t.Fatalf("memory = %d bytes, %g%%, want %d bytes, %g%%", sample.MemoryUsage, sample.MemoryPercent, test.bytes, test.percent)
+			}
+		})
+	}
+}
+
+func TestForgetRemovedMonitors(t *testing.T) {
+	t.Cleanup(func() {
+		forgetRemovedMonitors(nil)
+	})
+
+	for _, monitorID := range []string{"kept", "deleted"} {
+		history.cpu[monitorID] = cpuSample{container: 1, system: 1}
+		history.stored[monitorID] = time.Now()
+		ruleStates[monitorID] = map[string]*ruleState{"rule": {rule: db.StatRule{Metric: db.StatCPUPercent}}}
+	}
+
+	forgetRemovedMonitors([]ContainerDetails{{MonitorID: "kept"}})
+
+	if _, ok := history.cpu["kept"]; !ok {
+		t.Fatal("cpu sample of a monitored container was forgotten")
+	}
+	if _, ok := ruleStates["kept"]; !ok {
+		t.Fatal("rule states of a monitored container were forgotten")
+	}
+	if _, ok := history.cpu["deleted"]; ok {
+		t.Fatal("cpu sample of a deleted monitor was kept")
+	}
+	if _, ok := history.stored["deleted"]; ok {
+		t.Fatal("store time of a deleted monitor was kept")
+	}
+	if _, ok := ruleStates["deleted"]; ok {
+		t.Fatal("rule states of a deleted monitor were kept")
+	}
+}
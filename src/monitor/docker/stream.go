@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+// watchedActions are the container events that trigger a check right away.
+var watchedActions = []dockerevents.Action{
+	dockerevents.ActionStart,
+	dockerevents.ActionDie,
+	dockerevents.ActionOOM,
+	dockerevents.ActionHealthStatus,
+	dockerevents.ActionDestroy,
+}
+
+// monitored are the containers of the last poll, so docker events are matched
+// without a database query each. failed holds the monitors whose container
+// died or was OOM-killed since their last check.
+var monitored = struct {
+	sync.Mutex
+	containers []ContainerDetails
+	failed     map[string]bool
+}{failed: map[string]bool{}}
+
+// rememberMonitored replaces the containers docker events are matched against.
+func rememberMonitored(containers []ContainerDetails) {
+	monitored.Lock()
+	defer monitored.Unlock()
+
+	monitored.containers = containers
+}
+
+// confirmFailure marks the next check of the monitor as a confirmed failure.
+func confirmFailure(monitorID string) {
+	monitored.Lock()
+	defer monitored.Unlock()
+
+	monitored.failed[monitorID] = true
+}
+
+// confirmedFailure reports whether a die or oom event was seen for the
+// monitor since its last check, and forgets it.
+func confirmedFailure(monitorID string) bool {
+	monitored.Lock()
+	defer monitored.Unlock()
+
+	failed := monitored.failed[monitorID]
+	delete(monitored.failed, monitorID)
+
+	return failed
+}
+
+// WatchEvents follows the docker events stream and signals checks on changes
+// of monitored containers, until stop is closed. The stream is reconnected
+// when the daemon restarts, with a check afterwards for the events missed in
+// between. Set DOCKER_EVENTS to FALSE to only poll.
+func WatchEvents(logger zerolog.Logger, checks chan<- struct{}, stop chan struct{}) {
+	if os.Getenv("DOCKER_EVENTS") == "FALSE" {
+		logger.Info().Msg("DOCKER_EVENTS is set to false, only polling containers")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	retry := time.Second
+	for {
+		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if err == nil {
+			connected := time.Now()
+			err = followEvents(ctx, cli, logger, checks)
+			cli.Close()
+			if time.Since(connected) > time.Minute {
+				retry = time.Second
+			}
+		}
+		if ctx.Err() != nil {
+			logger.Info().Msg("Docker events thread exiting")
+			return
+		}
+
+		logger.Warn().Err(err).Msgf("Docker events stream lost, reconnecting in %s", retry)
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return
+		}
+		if retry < time.Minute {
+			retry *= 2
+		}
+	}
+}
+
+func followEvents(ctx context.Context, cli *client.Client, logger zerolog.Logger, checks chan<- struct{}) error {
+	args := filters.NewArgs(filters.Arg("type", string(dockerevents.ContainerEventType)))
+	for _, action := range watchedActions {
+		args.Add("event", string(action))
+	}
+
+	messages, errs := cli.Events(ctx, dockerevents.ListOptions{Filters: args})
+	logger.Info().Msg("Following docker events")
+	// events may have been missed while the stream was down
+	signal(checks)
+
+	for {
+		select {
+		case message := <-messages:
+			name := message.Actor.Attributes["name"]
+			monitorID, ok := isMonitored(message.Actor.Attributes[MonitorIDLabel], name)
+			if !ok {
+				continue
+			}
+			logger.Debug().Msgf("Docker event %s for container %s", message.Action, name)
+
+			// the daemon saw the container stop, the next check needs no
+			// further failed checks to mark it down
+			if message.Action == dockerevents.ActionDie || message.Action == dockerevents.ActionOOM {
+				confirmFailure(monitorID)
+			}
+			signal(checks)
+
+		case err := <-errs:
+			return err
+		}
+	}
+}
+
+// signal asks for a check without blocking, a pending check covers every
+// event that arrives before it runs.
+func signal(checks chan<- struct{}) {
+	select {
+	case checks <- struct{}{}:
+	default:
+	}
+}
+
+// isMonitored returns the monitor of a container, matching its identity label
+// first, like findContainer, and its name otherwise.
+func isMonitored(monitorID string, name string) (string, bool) {
+	monitored.Lock()
+	defer monitored.Unlock()
+
+	for _, desired := range monitored.containers {
+		if (monitorID != "" && desired.MonitorID == monitorID) || strings.TrimPrefix(desired.Name, "/") == strings.TrimPrefix(name, "/") {
+			return desired.MonitorID, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"github.com/rs/zerolog"
+)
+
+func TestIsMonitored(t *testing.T) {
+	rememberMonitored([]ContainerDetails{
+		{MonitorID: "m-web", Name: "web"},
+		{MonitorID: "m-db", Name: "/db"},
+	})
+	t.Cleanup(func() { rememberMonitored(nil) })
+
+	tests := []struct {
+		label, name string
+		want        string
+		ok          bool
+	}{
+		{"m-web", "renamed", "m-web", true},
+		{"", "web", "m-web", true},
+		{"", "/web", "m-web", true},
+		{"", "db", "m-db", true},
+		{"m-other", "cache", "", false},
+		{"", "", "", false},
+	}
+
+	for _, test := range tests {
+		if got, ok := isMonitored(test.label, test.name); got != test.want || ok != test.ok {
+			t.Errorf("isMonitored(%q, %q) = %q, %v, want %q, %v", test.label, test.name, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestConfirmedFailureIsTakenOnce(t *testing.T) {
+	if confirmedFailure("m-web") {
+		t.Fatal("no failure was confirmed yet")
+	}
+
+	confirmFailure("m-web")
+	if !confirmedFailure("m-web") {
+		t.Fatal("a die event should confirm the next check")
+	}
+	if confirmedFailure("m-web") {
+		t.Fatal("the confirmation covers a single check")
+	}
+}
+
+func TestFollowEventsConfirmsFailures(t *testing.T) {
+	rememberMonitored([]ContainerDetails{
+		{MonitorID: "m-web", Name: "/web"},
+		{MonitorID: "m-db", Name: "/db"},
+		{MonitorID: "m-api", Name: "/api"},
+	})
+	t.Cleanup(func() { rememberMonitored(nil) })
+
+	messages := []dockerevents.Message{
+		{Type: dockerevents.ContainerEventType, Action: dockerevents.ActionDie, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "web"}}},
+		{Type: dockerevents.ContainerEventType, Action: dockerevents.ActionOOM, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "renamed", MonitorIDLabel: "m-db"}}},
+		{Type: dockerevents.ContainerEventType, Action: dockerevents.ActionStart, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "api"}}},
+		{Type: dockerevents.ContainerEventType, Action: dockerevents.ActionDie, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "cache"}}},
+	}
+
+	// the stream ends after the messages, which ends followEvents
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/events") {
+			http.NotFound(w, r)
+			return
+		}
+		for _, message := range messages {
+			json.NewEncoder(w).Encode(message)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithVersion("1.43"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cli.Close() })
+
+	checks := make(chan struct{}, 1)
+	followEvents(context.Background(), cli, zerolog.Nop(), checks)
+
+	if len(checks) != 1 {
+		t.Fatal("no check was signalled")
+	}
+	for monitorID, want := range map[string]bool{"m-web": true, "m-db": true, "m-api": false, "cache": false} {
+		if got := confirmedFailure(monitorID); got != want {
+			t.Errorf("confirmedFailure(%s) = %v, want %v", monitorID, got, want)
+		}
+	}
+}
@@ -1,14 +1,48 @@
 package docker
 
-import "github.com/docker/docker/api/types/container"
+import (
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/docker/docker/api/types/container"
+)
 
 type ContainerDetails struct {
-	Name    string
-	Configs container.Config
+	MonitorID string
+	Name      string
+	Critical  bool
+	Tags      []string
+	// FailureThreshold and RecoveryThreshold are 0 for the global defaults
+	FailureThreshold  int
+	RecoveryThreshold int
+	RestartPolicy     db.RestartPolicy
+	// UnhealthyGrace is how long a container may fail its healthcheck before
+	// it is restarted, 0 never restarts unhealthy containers
+	UnhealthyGrace time.Duration
+	StatRules      []db.StatRule
+	Configs        container.Config
+	HostConfig     container.HostConfig
 }
 
 type ContainerStatus struct {
 	IsRunning   bool
 	ContainerID string
 	Name        string
+	// State as reported by docker, or "missing" when no container was found
+	State string
+	// ExitCode, OOMKilled and FinishedAt of a stopped container, read with ContainerInspect
+	ExitCode     int
+	OOMKilled    bool
+	FinishedAt   time.Time
+	RestartCount int
+	// Failure classifies a stopped container, see FailureCrash and the like
+	Failure string
+	// Health is the docker healthcheck status of a running container,
+	// starting, healthy or unhealthy, and empty without a healthcheck
+	Health string
+	// HealthLog is the output of the latest healthcheck of an unhealthy container
+	HealthLog string
+	// Drift describes an image that differs from the one of the monitor, the
+	// container is still the monitored one
+	Drift string
 }
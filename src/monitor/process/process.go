@@ -5,13 +5,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"time"
 
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/maintenance"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/PayCryps/WatchdogGo/src/monitor/check"
+	"github.com/PayCryps/WatchdogGo/src/restart"
 	"github.com/rs/zerolog"
 )
 
-var lastRestart = make(map[string]time.Time)
+var tracker = monitor.NewTracker()
 
 func MonitorProcess(logger zerolog.Logger, processStop chan struct{}) {
 	logger.Info().Msg("Process Monitor thread started")
@@ -28,10 +35,13 @@ func MonitorProcess(logger zerolog.Logger, processStop chan struct{}) {
 	ticker := time.NewTicker(time.Second * time.Duration(haltDuration))
 	defer ticker.Stop()
 
+	thresholds := monitor.DefaultThresholds(logger)
+	restartDefaults := restart.Defaults(logger)
+
 	for {
 		select {
 		case <-ticker.C:
-			monitorProcesses(logger)
+			monitorProcesses(logger, thresholds, restartDefaults)
 
 		case <-processStop:
 			logger.Info().Msg("Process thread exiting")
@@ -40,50 +50,92 @@ func MonitorProcess(logger zerolog.Logger, processStop chan struct{}) {
 	}
 }
 
-func monitorProcesses(logger zerolog.Logger) {
-	// dummy process to monitor
-	desiredProcess := DbPm2Process{
-		Name:    "test",
-		Command: "cargo run",
-		PWD:     "/Users/root/code/logging",
-		Env:     []map[string]string{{"RUST_ENV": "production"}},
+func monitorProcesses(logger zerolog.Logger, thresholds monitor.Thresholds, restartDefaults db.RestartPolicy) {
+	var desiredProcesses []db.DbPm2Process
+	if err := db.DB.Where("enabled = ?", true).Find(&desiredProcesses).Error; err != nil {
+		logger.Error().Err(err).Msg("Failed to load pm2 processes")
+		return
+	}
+	if len(desiredProcesses) == 0 {
+		return
 	}
-
-	// Todo: get the list of process to monitor from the db
-	desiredProcesses := []DbPm2Process{}
-	desiredProcesses = append(desiredProcesses, desiredProcess)
 
 	processesStatus := IsProcessesRunning(desiredProcesses, logger)
+	suppressions := maintenance.Load(logger)
 
-	for _, p := range processesStatus {
+	for i, p := range processesStatus {
+		desired := desiredProcesses[i]
 
-		if p.Status == "stopped" {
-			if time.Since(lastRestart[p.Name]) < 2*time.Second {
-				continue
-			}
+		target := check.Target{Kind: "Process", Type: db.MonitorTypeProcess, ID: desired.ID, Name: p.Name, Tags: desired.Tags}
+		result := check.Observe(logger, tracker, thresholds.For(desired.FailureThreshold, desired.RecoveryThreshold), suppressions, target, p.Status == "online", "status: "+pm2Status(p), func(state monitor.State) events.Source {
+			return processSource(desired, p, state)
+		})
+
+		if !result.Down(logger) {
+			continue
+		}
+
+		policy := restart.Effective(desired.RestartPolicy, restartDefaults)
+		// processes missing from pm2 count as failed too
+		failed := p.Status == "start" || p.Pm2Status == "errored" || p.ExitCode != 0
+		result.Remediate(logger, policy, failed, func(source *events.Source) bool {
+			return remediateProcess(desired, p, source, logger)
+		})
+	}
+}
 
-			logger.Warn().Msgf("Restarting %s (status: %s, pid: %d)", p.Name, p.Status, p.PID)
-			RestartProcess(p.PmId, p.Name, logger)
-			lastRestart[p.Name] = time.Now()
+// remediateProcess restarts a stopped process or starts a missing one.
+// It returns false when no action was taken.
+func remediateProcess(desired db.DbPm2Process, p ProcessStatus, source *events.Source, logger zerolog.Logger) bool {
+	if p.Status == "stopped" {
+		logger.Warn().Msgf("Restarting %s (status: %s, pid: %d)", p.Name, p.Status, p.PID)
+		events.Remediate(source, "pm2 restart", func() error {
+			return RestartProcess(p.PmId, p.Name, logger)
+		})
+		return true
+	}
 
-			// Allow time for PM2 to update status
-			time.Sleep(2 * time.Second)
+	ProcessStart := os.Getenv("PROCESS_START")
+	if ProcessStart == "FALSE" {
+		logger.Info().Msg("PROCESS_START is set to false, not starting container")
+		return false
+	}
+	if desired.StartPolicy == db.StartPolicyManual {
+		logger.Info().Msgf("%s has a manual start policy, not starting process", p.Name)
+		return false
+	}
 
-		} else if p.Status == "start" {
-			ProcessStart := os.Getenv("PROCESS_START")
-			if ProcessStart == "FALSE" {
-				logger.Info().Msg("PROCESS_START is set to false, not starting container")
-				continue
-			}
+	logger.Info().Msgf("Starting %s", p.Name)
+	events.Remediate(source, "pm2 start", func() error {
+		return StartProcess(desired, logger)
+	})
+	return true
+}
 
-			logger.Info().Msgf("Starting %s", p.Name)
-			StartProcess(desiredProcess, logger)
-		}
+func processSource(desired db.DbPm2Process, p ProcessStatus, state monitor.State) events.Source {
+	return events.Source{
+		MonitorType: db.MonitorTypeProcess,
+		MonitorID:   desired.ID,
+		MonitorName: desired.Name,
+		Critical:    desired.Critical,
+		State:       state,
+		Since:       tracker.Since(desired.ID),
+		Status:      pm2Status(p),
+		PID:         p.PID,
+		Time:        time.Now(),
+	}
+}
+
+// pm2Status reports processes pm2 does not know about as missing.
+func pm2Status(p ProcessStatus) string {
+	if p.Status == "start" {
+		return "missing"
 	}
 
+	return p.Status
 }
 
-func IsProcessesRunning(desiredProcess []DbPm2Process, logger zerolog.Logger) []ProcessStatus {
+func IsProcessesRunning(desiredProcess []db.DbPm2Process, logger zerolog.Logger) []ProcessStatus {
 	processes := GetPm2Processes(logger)
 
 	processStatus := []ProcessStatus{}
@@ -106,13 +158,15 @@ func IsProcessesRunning(desiredProcess []DbPm2Process, logger zerolog.Logger) []
 				} else {
 					logger.Error().Msgf("Process %s is not running, Status: %s", p.Name, p.Status)
 					processStatus = append(processStatus, ProcessStatus{
-						Status:  "stopped",
-						PID:     p.PID,
-						Name:    p.Name,
-						PmId:    p.PmID,
-						Command: p.Command,
-						Env:     p.Env,
-						PWD:     p.PWD,
+						Status:    "stopped",
+						PID:       p.PID,
+						Name:      p.Name,
+						PmId:      p.PmID,
+						Command:   p.Command,
+						Env:       p.Env,
+						PWD:       p.PWD,
+						Pm2Status: p.Status,
+						ExitCode:  p.ExitCode,
 					})
 				}
 				found = true
@@ -156,29 +210,48 @@ func GetPm2Processes(logger zerolog.Logger) []Pm2Process {
 		command := p.Pm2Env.Args[len(p.Pm2Env.Args)-1]
 
 		processes = append(processes, Pm2Process{
-			PmID:    p.PmID,
-			Name:    p.Name,
-			PID:     p.PID,
-			Status:  p.Pm2Env.Status,
-			Command: command,
-			PWD:     p.Pm2Env.PWD,
-			Env:     p.Pm2Env.Env,
+			PmID:     p.PmID,
+			Name:     p.Name,
+			PID:      p.PID,
+			Status:   p.Pm2Env.Status,
+			Command:  command,
+			PWD:      p.Pm2Env.PWD,
+			Env:      p.Pm2Env.Env,
+			PmUptime: p.Pm2Env.PmUptime,
+			ExitCode: p.Pm2Env.ExitCode,
 		})
 	}
 
 	return processes
 }
 
-func RestartProcess(pmID int, processName string, logger zerolog.Logger) {
+func RestartProcess(pmID int, processName string, logger zerolog.Logger) error {
 	cmd := exec.Command("pm2", "restart", fmt.Sprintf("%d", pmID))
 	if err := cmd.Run(); err != nil {
 		logger.Error().Msgf("Error restarting process: %s", err)
+		return err
 	}
+
+	return nil
 }
 
-func StartProcess(process DbPm2Process, logger zerolog.Logger) {
-	cmd := exec.Command("pm2", "start", process.Command, "--name", process.Name)
+func StartProcess(process db.DbPm2Process, logger zerolog.Logger) error {
+	args := []string{"start", process.Command, "--name", process.Name}
+	if process.PWD != "" {
+		args = append(args, "--cwd", process.PWD)
+	}
+	if process.Interpreter != "" {
+		args = append(args, "--interpreter", process.Interpreter)
+	}
+	if len(process.Args) > 0 {
+		args = append(args, "--")
+		args = append(args, process.Args...)
+	}
+
+	cmd := exec.Command("pm2", args...)
 	cmd.Dir = process.PWD
+	// pm2 hands the environment of the cli invocation over to the new process
+	cmd.Env = append(os.Environ(), EnvList(process.Env)...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -187,7 +260,7 @@ func StartProcess(process DbPm2Process, logger zerolog.Logger) {
 			Str("output", string(output)).
 			Str("directory", process.PWD).
 			Msg("Failed to start process")
-		return
+		return err
 	}
 
 	logger.Info().
@@ -195,4 +268,19 @@ func StartProcess(process DbPm2Process, logger zerolog.Logger) {
 		Str("directory", process.PWD).
 		Msg("Process started successfully")
 
+	return nil
+}
+
+// EnvList flattens the stored env maps into KEY=VALUE pairs, sorted so the
+// resulting environment is stable between starts.
+func EnvList(env []map[string]string) []string {
+	list := []string{}
+	for _, vars := range env {
+		for key, value := range vars {
+			list = append(list, key+"="+value)
+		}
+	}
+	sort.Strings(list)
+
+	return list
 }
@@ -0,0 +1,118 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/rs/zerolog"
+)
+
+// fakePm2 puts a pm2 on the PATH that answers jlist with list and records the
+// arguments and environment of any other command in the returned directory.
+func fakePm2(t *testing.T, list string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jlist.json"), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+dir=$(dirname "$0")
+if [ "$1" = jlist ]; then
+	cat "$dir/jlist.json"
+	exit 0
+fi
+printf '%s\n' "$@" > "$dir/args"
+env > "$dir/env"
+pwd > "$dir/pwd"
+`
+	if err := os.WriteFile(filepath.Join(dir, "pm2"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return dir
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestEnvList(t *testing.T) {
+	env := []map[string]string{{"PORT": "8080", "APP_ENV": "production"}, {"DATABASE_URL": "postgres://db"}}
+
+	want := []string{"APP_ENV=production", "DATABASE_URL=postgres://db", "PORT=8080"}
+	if got := EnvList(env); !slices.Equal(got, want) {
+		t.Fatalf("EnvList() = %v, want %v", got, want)
+	}
+	if got := EnvList(nil); len(got) != 0 {
+		t.Fatalf("EnvList(nil) = %v", got)
+	}
+}
+
+func TestStartProcess(t *testing.T) {
+	dir := fakePm2(t, "[]")
+	work := t.TempDir()
+
+	process := db.DbPm2Process{
+		Name:        "api",
+		Command:     "server.js",
+		Env:         []map[string]string{{"PORT": "8080"}},
+		PWD:         work,
+		Interpreter: "node",
+		Args:        []string{"--verbose"},
+	}
+	if err := StartProcess(process, zerolog.Nop()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"start", "server.js", "--name", "api", "--cwd", work, "--interpreter", "node", "--", "--verbose"}
+	if args := readLines(t, filepath.Join(dir, "args")); !slices.Equal(args, want) {
+		t.Fatalf("pm2 %v, want pm2 %v", args, want)
+	}
+	if env := readLines(t, filepath.Join(dir, "env")); !slices.Contains(env, "PORT=8080") {
+		t.Fatalf("pm2 ran without the process env: %v", env)
+	}
+	if pwd := readLines(t, filepath.Join(dir, "pwd")); pwd[0] != work {
+		t.Fatalf("pm2 ran in %s, want %s", pwd[0], work)
+	}
+}
+
+func TestIsProcessesRunning(t *testing.T) {
+	fakePm2(t, `[
+		{"pm_id": 0, "pid": 42, "name": "api", "pm2_env": {"status": "online", "args": ["server.js"]}},
+		{"pm_id": 1, "pid": 0, "name": "worker", "pm2_env": {"status": "errored", "exit_code": 1, "args": ["worker.js"]}}
+	]`)
+
+	desired := []db.DbPm2Process{
+		{Name: "api", Command: "server.js"},
+		{Name: "worker", Command: "worker.js"},
+		{Name: "cron", Command: "cron.js", Env: []map[string]string{{"TZ": "UTC"}}},
+	}
+	statuses := IsProcessesRunning(desired, zerolog.Nop())
+	if len(statuses) != len(desired) {
+		t.Fatalf("got %d statuses for %d processes", len(statuses), len(desired))
+	}
+
+	if api := statuses[0]; api.Status != "online" || api.PID != 42 {
+		t.Fatalf("api = %+v", api)
+	}
+	if worker := statuses[1]; worker.Status != "stopped" || worker.Pm2Status != "errored" || worker.ExitCode != 1 || worker.PmId != 1 {
+		t.Fatalf("worker = %+v", worker)
+	}
+	// missing processes keep their stored definition so they can be started
+	if cron := statuses[2]; cron.Status != "start" || cron.Command != "cron.js" || cron.Env[0]["TZ"] != "UTC" {
+		t.Fatalf("cron = %+v", cron)
+	}
+}
@@ -6,6 +6,7 @@ type RawPm2Process struct {
 	Name   string `json:"name"`
 	Pm2Env struct {
 		Status     string              `json:"status"`
+		ExitCode   int                 `json:"exit_code"`
 		PmUptime   int64               `json:"pm_uptime"`
 		PmExecPath string              `json:"pm_exec_path"`
 		PWD        string              `json:"PWD"`
@@ -19,20 +20,15 @@ type RawPm2Process struct {
 }
 
 type Pm2Process struct {
-	PmID    int
-	Name    string
-	PID     int
-	Status  string
-	PWD     string
-	Command string
-	Env     []map[string]string
-}
-
-type DbPm2Process struct {
-	Name    string              `json:"name"`
-	Command string              `json:"command"`
-	Env     []map[string]string `json:"env"`
-	PWD     string              `json:"pwd"`
+	PmID     int
+	Name     string
+	PID      int
+	Status   string
+	PWD      string
+	Command  string
+	Env      []map[string]string
+	PmUptime int64
+	ExitCode int
 }
 
 type ProcessStatus struct {
@@ -43,4 +39,7 @@ type ProcessStatus struct {
 	Command string
 	Env     []map[string]string
 	PWD     string
+	// Pm2Status and ExitCode as reported by pm2 for stopped processes
+	Pm2Status string
+	ExitCode  int
 }
@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/rs/zerolog"
+)
+
+type State string
+
+const (
+	StateUp State = "up"
+	// StateSuspect is a monitor that failed fewer checks than its failure threshold
+	StateSuspect State = "suspect"
+	StateDown    State = "down"
+	// StateFlapping is a monitor that went up and down too often within the
+	// flap window, it is not remediated until it settles
+	StateFlapping State = "flapping"
+)
+
+// Thresholds decide how many checks it takes to change the state of a monitor.
+type Thresholds struct {
+	// Failures is the number of consecutive failed checks before a monitor is down
+	Failures int
+	// Successes is the number of consecutive passed checks before a down monitor is up
+	Successes int
+	// FlapCount is the number of up/down flips within FlapWindow that make a
+	// monitor flapping, 0 disables flap detection
+	FlapCount  int
+	FlapWindow time.Duration
+}
+
+// DefaultThresholds reads the thresholds from the environment:
+// FAILURE_THRESHOLD, RECOVERY_THRESHOLD, FLAP_THRESHOLD and FLAP_WINDOW (seconds).
+func DefaultThresholds(logger zerolog.Logger) Thresholds {
+	return Thresholds{
+		Failures:   utils.EnvInt(logger, "FAILURE_THRESHOLD", 2),
+		Successes:  utils.EnvInt(logger, "RECOVERY_THRESHOLD", 1),
+		FlapCount:  utils.EnvInt(logger, "FLAP_THRESHOLD", 6),
+		FlapWindow: time.Duration(utils.EnvInt(logger, "FLAP_WINDOW", 600)) * time.Second,
+	}
+}
+
+// For returns the thresholds with the failure and success counts of a single
+// monitor. Counts below 1 keep the defaults.
+func (t Thresholds) For(failures int, successes int) Thresholds {
+	if failures > 0 {
+		t.Failures = failures
+	}
+	if successes > 0 {
+		t.Successes = successes
+	}
+
+	return t
+}
+
+// Transition is the outcome of a single check.
+type Transition struct {
+	Previous State
+	Current  State
+	// Seen is false the first time a monitor is checked, Previous is empty then
+	Seen bool
+}
+
+// Changed reports whether the check moved the monitor to another state.
+func (t Transition) Changed() bool {
+	return !t.Seen || t.Previous != t.Current
+}
+
+type trackedState struct {
+	// health is the state ignoring flap detection, up, suspect or down
+	health    State
+	flapping  bool
+	since     time.Time
+	failures  int
+	successes int
+	// flips are the times the monitor went from up to down or back
+	flips []time.Time
+}
+
+func (s *trackedState) state() State {
+	if s.flapping {
+		return StateFlapping
+	}
+
+	return s.health
+}
+
+// Tracker runs the state machine of every monitor so callers can react to
+// transitions instead of every failed check.
+type Tracker struct {
+	mu     sync.Mutex
+	states map[string]*trackedState
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{states: make(map[string]*trackedState)}
+}
+
+// Observe feeds the result of a check into the state machine of a monitor.
+func (t *Tracker) Observe(monitorID string, healthy bool, thresholds Thresholds) Transition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	current, seen := t.states[monitorID]
+	if !seen {
+		current = &trackedState{health: StateUp, since: now}
+		t.states[monitorID] = current
+	}
+
+	transition := Transition{Seen: seen}
+	if seen {
+		transition.Previous = current.state()
+	}
+
+	health := current.health
+	if healthy {
+		current.failures = 0
+		current.successes++
+		if health == StateSuspect || (health == StateDown && current.successes >= thresholds.Successes) {
+			health = StateUp
+		}
+	} else {
+		current.successes = 0
+		current.failures++
+		if health != StateDown {
+			health = StateSuspect
+			if current.failures >= thresholds.Failures {
+				health = StateDown
+			}
+		}
+	}
+
+	// suspect is not an outage, only flips between up and down count
+	if seen && health != current.health && health != StateSuspect && (health == StateDown || current.health == StateDown) {
+		current.flips = append(current.flips, now)
+	}
+	current.health = health
+
+	recent := current.flips[:0]
+	for _, flip := range current.flips {
+		if now.Sub(flip) < thresholds.FlapWindow {
+			recent = append(recent, flip)
+		}
+	}
+	current.flips = recent
+
+	if thresholds.FlapCount > 0 && len(current.flips) >= thresholds.FlapCount {
+		current.flapping = true
+	} else if len(current.flips) == 0 {
+		current.flapping = false
+	}
+
+	transition.Current = current.state()
+	if transition.Changed() && seen {
+		current.since = now
+	}
+
+	return transition
+}
+
+// State returns the current state of a monitor and whether it was ever checked.
+func (t *Tracker) State(monitorID string) (State, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, seen := t.states[monitorID]
+	if !seen {
+		return "", false
+	}
+
+	return current.state(), true
+}
+
+// Since returns when the monitor entered its current state, or the zero time
+// if it was never observed.
+func (t *Tracker) Since(monitorID string) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, seen := t.states[monitorID]
+	if !seen {
+		return time.Time{}
+	}
+
+	return current.since
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackerTransitions(t *testing.T) {
+	noFlap := Thresholds{Failures: 2, Successes: 1}
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		checks     []bool
+		want       []State
+	}{
+		{
+			name:       "healthy monitor stays up",
+			thresholds: noFlap,
+			checks:     []bool{true, true, true},
+			want:       []State{StateUp, StateUp, StateUp},
+		},
+		{
+			name:       "single failure is suspect",
+			thresholds: noFlap,
+			checks:     []bool{true, false, true},
+			want:       []State{StateUp, StateSuspect, StateUp},
+		},
+		{
+			name:       "down after the failure threshold",
+			thresholds: noFlap,
+			checks:     []bool{false, false, false},
+			want:       []State{StateSuspect, StateDown, StateDown},
+		},
+		{
+			name:       "failure threshold of one goes down right away",
+			thresholds: Thresholds{Failures: 1, Successes: 1},
+			checks:     []bool{true, false},
+			want:       []State{StateUp, StateDown},
+		},
+		{
+			name:       "recovery waits for the success threshold",
+			thresholds: Thresholds{Failures: 1, Successes: 3},
+			checks:     []bool{false, true, true, false, true, true, true},
+			want:       []State{StateDown, StateDown, StateDown, StateDown, StateDown, StateDown, StateUp},
+		},
+		{
+			name:       "flapping after too many flips",
+			thresholds: Thresholds{Failures: 1, Successes: 1, FlapCount: 3, FlapWindow: time.Hour},
+			checks:     []bool{true, false, true, false, true},
+			want:       []State{StateUp, StateDown, StateUp, StateFlapping, StateFlapping},
+		},
+		{
+			name:       "suspect does not count as a flip",
+			thresholds: Thresholds{Failures: 2, Successes: 1, FlapCount: 2, FlapWindow: time.Hour},
+			checks:     []bool{true, false, true, false, true, false, true},
+			want:       []State{StateUp, StateSuspect, StateUp, StateSuspect, StateUp, StateSuspect, StateUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+			for i, healthy := range tt.checks {
+				transition := tracker.Observe("monitor", healthy, tt.thresholds)
+				if transition.Current != tt.want[i] {
+					t.Fatalf("check %d: got %s, want %s", i+1, transition.Current, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTrackerTransitionChanged(t *testing.T) {
+	tracker := NewTracker()
+	thresholds := Thresholds{Failures: 1, Successes: 1}
+
+	first := tracker.Observe("monitor", true, thresholds)
+	if first.Seen || !first.Changed() {
+		t.Fatalf("first check should be an unseen change, got %+v", first)
+	}
+
+	same := tracker.Observe("monitor", true, thresholds)
+	if same.Changed() {
+		t.Fatalf("repeated healthy check should not change, got %+v", same)
+	}
+
+	down := tracker.Observe("monitor", false, thresholds)
+	if !down.Changed() || down.Previous != StateUp || down.Current != StateDown {
+		t.Fatalf("got %+v, want up to down", down)
+	}
+
+	if state, seen := tracker.State("monitor"); !seen || state != StateDown {
+		t.Fatalf("State() = %s, %t, want down", state, seen)
+	}
+	if _, seen := tracker.State("other"); seen {
+		t.Fatal("unknown monitor should not be seen")
+	}
+}
+
+func TestTrackerFlappingSettles(t *testing.T) {
+	tracker := NewTracker()
+	thresholds := Thresholds{Failures: 1, Successes: 1, FlapCount: 2, FlapWindow: 20 * time.Millisecond}
+
+	tracker.Observe("monitor", true, thresholds)
+	tracker.Observe("monitor", false, thresholds)
+	if transition := tracker.Observe("monitor", true, thresholds); transition.Current != StateFlapping {
+		t.Fatalf("got %s, want flapping", transition.Current)
+	}
+
+	time.Sleep(2 * thresholds.FlapWindow)
+
+	if transition := tracker.Observe("monitor", true, thresholds); transition.Current != StateUp {
+		t.Fatalf("got %s, want up once the flips left the window", transition.Current)
+	}
+}
+
+func TestThresholdsFor(t *testing.T) {
+	defaults := Thresholds{Failures: 2, Successes: 1, FlapCount: 6, FlapWindow: time.Minute}
+
+	if got := defaults.For(0, 0); got != defaults {
+		t.Fatalf("For(0, 0) = %+v, want defaults", got)
+	}
+
+	got := defaults.For(5, 3)
+	if got.Failures != 5 || got.Successes != 3 || got.FlapCount != 6 {
+		t.Fatalf("For(5, 3) = %+v", got)
+	}
+}
@@ -0,0 +1,239 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+// Colours used by both chat backends
+const (
+	colourDown       = 0xE01E5A
+	colourRestarting = 0xECB22E
+	colourRecovered  = 0x2EB67D
+)
+
+func eventColour(kind EventKind) int {
+	switch kind {
+	case EventDown, EventEscalated, EventExhausted:
+		return colourDown
+	case EventRestarted, EventFlapping, EventThreshold:
+		return colourRestarting
+	default:
+		return colourRecovered
+	}
+}
+
+// chatField is a label/value pair rendered as a field in chat messages.
+type chatField struct {
+	Name  string
+	Value string
+}
+
+func chatFields(event Event) []chatField {
+	target := "Container"
+	switch event.MonitorType {
+	case db.MonitorTypeProcess:
+		target = "PM2 process"
+	case db.MonitorTypeCompose:
+		target = "Compose project"
+	}
+
+	fields := []chatField{
+		{Name: target, Value: event.MonitorName},
+		{Name: "Host", Value: event.Host},
+	}
+	if event.Status != "" {
+		fields = append(fields, chatField{Name: "Status", Value: event.Status})
+	}
+	if event.Failure != "" {
+		fields = append(fields, chatField{Name: "Failure", Value: event.Failure})
+	}
+	if event.Health != "" {
+		fields = append(fields, chatField{Name: "Health", Value: event.Health})
+	}
+	if event.HealthLog != "" {
+		fields = append(fields, chatField{Name: "Healthcheck output", Value: event.HealthLog})
+	}
+	if event.Drift != "" {
+		fields = append(fields, chatField{Name: "Drift", Value: event.Drift})
+	}
+	if event.Downtime > 0 {
+		fields = append(fields, chatField{Name: "Downtime", Value: event.Downtime.Round(time.Second).String()})
+	}
+	if event.ContainerID != "" {
+		fields = append(fields, chatField{Name: "Container ID", Value: shortID(event.ContainerID)})
+	}
+	if event.PID != 0 {
+		fields = append(fields, chatField{Name: "PID", Value: fmt.Sprintf("%d", event.PID)})
+	}
+	if event.Remediation != nil {
+		fields = append(fields, chatField{Name: "Action", Value: event.Remediation.String()})
+	}
+
+	return fields
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
+
+func chatTitle(event Event) string {
+	switch event.Kind {
+	case EventDown, EventEscalated:
+		return fmt.Sprintf("\U0001F534 %s is down", event.MonitorName)
+	case EventRestarted:
+		return fmt.Sprintf("\U0001F7E1 %s is restarting", event.MonitorName)
+	case EventFlapping:
+		return fmt.Sprintf("\U0001F7E0 %s is flapping", event.MonitorName)
+	case EventExhausted:
+		return fmt.Sprintf("\U0001F534 %s could not be restarted", event.MonitorName)
+	case EventThreshold:
+		return fmt.Sprintf("\U0001F7E0 %s crossed a resource threshold", event.MonitorName)
+	default:
+		return fmt.Sprintf("\U0001F7E2 %s recovered", event.MonitorName)
+	}
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// SlackNotifier posts Block Kit messages to a Slack incoming webhook.
+type SlackNotifier struct {
+	Client *http.Client
+}
+
+func NewSlackNotifier() *SlackNotifier {
+	return &SlackNotifier{Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *SlackNotifier) Destination(recipient Recipient) string {
+	return recipient.Subscription.SlackWebhookURL
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	url := recipient.Subscription.SlackWebhookURL
+	if url == "" {
+		return fmt.Errorf("subscription %s has no slack webhook url", recipient.Subscription.ID)
+	}
+
+	return postJSON(ctx, n.Client, url, SlackPayload(event))
+}
+
+// SlackPayload renders the event as Block Kit blocks wrapped in a coloured attachment.
+func SlackPayload(event Event) map[string]any {
+	fields := []map[string]any{}
+	for _, field := range chatFields(event) {
+		fields = append(fields, map[string]any{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", field.Name, field.Value),
+		})
+	}
+
+	blocks := []map[string]any{
+		{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": "*" + chatTitle(event) + "*"},
+		},
+		{
+			"type":   "section",
+			"fields": fields,
+		},
+		{
+			"type": "context",
+			"elements": []map[string]any{
+				{"type": "mrkdwn", "text": fmt.Sprintf("%s | %s", strings.ToUpper(string(event.Severity)), event.Time.Format(time.RFC1123))},
+			},
+		},
+	}
+
+	return map[string]any{
+		"text": event.Subject(),
+		"attachments": []map[string]any{
+			{
+				"color":  fmt.Sprintf("#%06X", eventColour(event.Kind)),
+				"blocks": blocks,
+			},
+		},
+	}
+}
+
+// DiscordNotifier posts embeds to a Discord webhook.
+type DiscordNotifier struct {
+	Client *http.Client
+}
+
+func NewDiscordNotifier() *DiscordNotifier {
+	return &DiscordNotifier{Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *DiscordNotifier) Destination(recipient Recipient) string {
+	return recipient.Subscription.DiscordWebhookURL
+}
+
+func (n *DiscordNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	url := recipient.Subscription.DiscordWebhookURL
+	if url == "" {
+		return fmt.Errorf("subscription %s has no discord webhook url", recipient.Subscription.ID)
+	}
+
+	return postJSON(ctx, n.Client, url, DiscordPayload(event))
+}
+
+// DiscordPayload renders the event as a single coloured embed.
+func DiscordPayload(event Event) map[string]any {
+	fields := []map[string]any{}
+	for _, field := range chatFields(event) {
+		fields = append(fields, map[string]any{
+			"name":   field.Name,
+			"value":  field.Value,
+			"inline": true,
+		})
+	}
+
+	return map[string]any{
+		"embeds": []map[string]any{
+			{
+				"title":     chatTitle(event),
+				"color":     eventColour(event.Kind),
+				"fields":    fields,
+				"footer":    map[string]any{"text": strings.ToUpper(string(event.Severity))},
+				"timestamp": event.Time.Format(time.RFC3339),
+			},
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+func TestEventColour(t *testing.T) {
+	tests := []struct {
+		kind   EventKind
+		colour int
+	}{
+		{EventDown, colourDown},
+		{EventEscalated, colourDown},
+		{EventExhausted, colourDown},
+		{EventRestarted, colourRestarting},
+		{EventFlapping, colourRestarting},
+		{EventThreshold, colourRestarting},
+		{EventRecovered, colourRecovered},
+	}
+
+	for _, test := range tests {
+		if colour := eventColour(test.kind); colour != test.colour {
+			t.Errorf("eventColour(%s) = %06X, want %06X", test.kind, colour, test.colour)
+		}
+	}
+}
+
+func TestChatFields(t *testing.T) {
+	event := NewEvent(EventRecovered, db.MonitorTypeProcess, "api", "api")
+	event.Host = "web-1"
+	event.Downtime = 90*time.Second + 400*time.Millisecond
+	event.ContainerID = "0123456789abcdef"
+
+	fields := map[string]string{}
+	for _, field := range chatFields(event) {
+		fields[field.Name] = field.Value
+	}
+
+	want := map[string]string{"PM2 process": "api", "Host": "web-1", "Downtime": "1m30s", "Container ID": "0123456789ab"}
+	for name, value := range want {
+		if fields[name] != value {
+			t.Errorf("field %s = %q, want %q", name, fields[name], value)
+		}
+	}
+	if _, ok := fields["PID"]; ok {
+		t.Error("PID field set without a pid")
+	}
+}
+
+// chatStub records the JSON payloads posted to it.
+func chatStub(t *testing.T, status int) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("content type %s", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid payload: %s", err)
+		}
+		payloads = append(payloads, payload)
+		w.WriteHeader(status)
+		io.WriteString(w, "no_text")
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &payloads
+}
+
+func TestSlackNotify(t *testing.T) {
+	server, payloads := chatStub(t, http.StatusOK)
+
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	recipient := Recipient{Subscription: db.Subscription{ID: "sub", SlackWebhookURL: server.URL}}
+	if err := NewSlackNotifier().Notify(context.Background(), recipient, event); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*payloads) != 1 {
+		t.Fatalf("posted %d payloads", len(*payloads))
+	}
+	payload := (*payloads)[0]
+	if payload["text"] != event.Subject() {
+		t.Fatalf("text = %v", payload["text"])
+	}
+	attachment := payload["attachments"].([]any)[0].(map[string]any)
+	if attachment["color"] != "#E01E5A" {
+		t.Fatalf("color = %v", attachment["color"])
+	}
+	blocks := attachment["blocks"].([]any)
+	title := blocks[0].(map[string]any)["text"].(map[string]any)["text"].(string)
+	if !strings.Contains(title, "web is down") {
+		t.Fatalf("title = %s", title)
+	}
+	fields := blocks[1].(map[string]any)["fields"].([]any)
+	if text := fields[0].(map[string]any)["text"]; text != "*Container*\nweb" {
+		t.Fatalf("first field = %v", text)
+	}
+}
+
+func TestDiscordNotify(t *testing.T) {
+	server, payloads := chatStub(t, http.StatusNoContent)
+
+	event := NewEvent(EventRestarted, db.MonitorTypeCompose, "shop", "shop")
+	recipient := Recipient{Subscription: db.Subscription{ID: "sub", DiscordWebhookURL: server.URL}}
+	if err := NewDiscordNotifier().Notify(context.Background(), recipient, event); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*payloads) != 1 {
+		t.Fatalf("posted %d payloads", len(*payloads))
+	}
+	embed := (*payloads)[0]["embeds"].([]any)[0].(map[string]any)
+	if embed["title"] != "\U0001F7E1 shop is restarting" {
+		t.Fatalf("title = %v", embed["title"])
+	}
+	if embed["color"] != float64(colourRestarting) {
+		t.Fatalf("color = %v", embed["color"])
+	}
+	field := embed["fields"].([]any)[0].(map[string]any)
+	if field["name"] != "Compose project" || field["value"] != "shop" || field["inline"] != true {
+		t.Fatalf("first field = %v", field)
+	}
+	if footer := embed["footer"].(map[string]any)["text"]; footer != "WARNING" {
+		t.Fatalf("footer = %v", footer)
+	}
+}
+
+func TestChatNotifyErrors(t *testing.T) {
+	server, _ := chatStub(t, http.StatusNotFound)
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+
+	if err := NewSlackNotifier().Notify(context.Background(), Recipient{Subscription: db.Subscription{ID: "sub"}}, event); err == nil {
+		t.Fatal("slack notified without a webhook url")
+	}
+	if err := NewDiscordNotifier().Notify(context.Background(), Recipient{Subscription: db.Subscription{ID: "sub"}}, event); err == nil {
+		t.Fatal("discord notified without a webhook url")
+	}
+
+	recipient := Recipient{Subscription: db.Subscription{ID: "sub", SlackWebhookURL: server.URL}}
+	err := NewSlackNotifier().Notify(context.Background(), recipient, event)
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "no_text") {
+		t.Fatalf("Notify() error = %v, want the status and body", err)
+	}
+}
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// EmailNotifier sends alerts through an SMTP server.
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	StartTLS bool
+}
+
+// NewEmailNotifierFromEnv reads the SMTP_* variables. It returns nil when
+// SMTP_HOST is not set.
+func NewEmailNotifierFromEnv() (*EmailNotifier, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		return nil, fmt.Errorf("SMTP_FROM is not set")
+	}
+
+	return &EmailNotifier{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+		StartTLS: os.Getenv("SMTP_STARTTLS") != "FALSE",
+	}, nil
+}
+
+func (n *EmailNotifier) Addr() string {
+	return net.JoinHostPort(n.Host, n.Port)
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	if recipient.User.Email == "" {
+		return fmt.Errorf("user %s has no email address", recipient.User.ID)
+	}
+
+	return n.Send(ctx, recipient.User.Email, event.Subject(), event.Details())
+}
+
+// Send delivers a plain text email to a single address.
+func (n *EmailNotifier) Send(ctx context.Context, to string, subject string, body string) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr())
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if n.StartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if n.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMessage(n.From, to, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func buildMessage(from string, to string, subject string, body string) []byte {
+	headers := []string{
+		"From: " + headerValue(from),
+		"To: " + headerValue(to),
+		"Subject: " + mime.QEncoding.Encode("utf-8", headerValue(subject)),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n") + "\r\n"
+
+	return []byte(message)
+}
+
+// headerValue drops line breaks so values cannot start new headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+// smtpSink is a minimal SMTP server keeping the messages it receives.
+type smtpSink struct {
+	listener net.Listener
+	auth     string
+	from     string
+	to       []string
+	messages chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &smtpSink{listener: listener, messages: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+
+	go sink.serve()
+
+	return sink
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *smtpSink) session(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 sink ready")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-sink")
+			text.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			_, credentials, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(credentials)
+			s.auth = string(decoded)
+			text.PrintfLine("235 authenticated")
+		case "MAIL":
+			s.from = arg
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			s.to = append(s.to, arg)
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.messages <- string(data)
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpSink) notifier() *EmailNotifier {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+
+	return &EmailNotifier{Host: host, Port: port, From: "watchdog@example.com"}
+}
+
+func (s *smtpSink) message(t *testing.T) *mail.Message {
+	t.Helper()
+
+	select {
+	case data := <-s.messages:
+		message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(data)))
+		if err != nil {
+			t.Fatalf("sink received an invalid message: %s", err)
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink received no message")
+	}
+
+	return nil
+}
+
+func TestEmailNotifySendsToSink(t *testing.T) {
+	sink := newSMTPSink(t)
+	notifier := sink.notifier()
+	notifier.Username = "watchdog"
+	notifier.Password = "secret"
+
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	event.Status = "exited"
+	recipient := Recipient{User: db.User{ID: "alice", Email: "alice@example.com"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, recipient, event); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	message := sink.message(t)
+	if sink.auth != "\x00watchdog\x00secret" {
+		t.Fatalf("sink got credentials %q", sink.auth)
+	}
+	if sink.from != "FROM:<watchdog@example.com>" || len(sink.to) != 1 || sink.to[0] != "TO:<alice@example.com>" {
+		t.Fatalf("sink got envelope from %q to %q", sink.from, sink.to)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != event.Subject() {
+		t.Fatalf("subject = %q, want %q", subject, event.Subject())
+	}
+	if message.Header.Get("To") != "alice@example.com" {
+		t.Fatalf("To = %q", message.Header.Get("To"))
+	}
+}
+
+func TestEmailSubjectCannotInjectHeaders(t *testing.T) {
+	sink := newSMTPSink(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	subject := "web is down\r\nBcc: mallory@example.com"
+	if err := sink.notifier().Send(ctx, "alice@example.com", subject, "body"); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	message := sink.message(t)
+	if bcc := message.Header.Get("Bcc"); bcc != "" {
+		t.Fatalf("subject injected a Bcc header: %q", bcc)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || decoded != "web is downBcc: mallory@example.com" {
+		t.Fatalf("subject = %q", decoded)
+	}
+}
+
+func TestEmailNotifyNeedsAddress(t *testing.T) {
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	err := (&EmailNotifier{}).Notify(context.Background(), Recipient{User: db.User{ID: "bob"}}, event)
+	if err == nil {
+		t.Fatal("users without an email address should be an error")
+	}
+}
@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"fmt"
+
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/monitor"
+	"github.com/rs/zerolog"
+)
+
+// Subscribe alerts subscribers about the state changes, successful
+// remediations and resource thresholds published by the monitors. Monitors
+// that went down while suppressed are alerted on once the suppression ends
+// and they are still down, their recovery is only alerted on after that.
+func Subscribe(logger zerolog.Logger) {
+	// what suppressed the down alert of a monitor, by monitor
+	deferred := map[string]string{}
+
+	events.Subscribe("notifier", func(e events.Event) {
+		switch e := e.(type) {
+		case events.CheckCompleted:
+			key := e.MonitorType + "/" + e.MonitorID
+			suppressed, ok := deferred[key]
+			if !ok || e.Suppressed != "" || (e.State != monitor.StateDown && e.State != monitor.StateFlapping) {
+				return
+			}
+			delete(deferred, key)
+
+			logger.Info().Msgf("%s %s is still down after %s", e.MonitorType, e.MonitorName, suppressed)
+			event := eventFrom(EventDown, e.Source, e.State)
+			event.Downtime = e.Time.Sub(e.Since)
+			event.Message = "Went down during " + suppressed
+			go Dispatch(logger, event)
+
+		case events.StateChanged:
+			key := e.MonitorType + "/" + e.MonitorID
+			if e.Suppressed != "" {
+				if e.State == monitor.StateDown || e.State == monitor.StateFlapping {
+					deferred[key] = e.Suppressed
+				} else if e.State == monitor.StateUp {
+					delete(deferred, key)
+				}
+				return
+			}
+
+			switch e.State {
+			case monitor.StateUp:
+				// the down alert of a monitor that recovered right as its suppression ended was never sent
+				if _, ok := deferred[key]; ok {
+					delete(deferred, key)
+					return
+				}
+				if e.Previous == monitor.StateDown || e.Previous == monitor.StateFlapping {
+					logger.Info().Msgf("%s %s recovered", e.MonitorType, e.MonitorName)
+					event := eventFrom(EventRecovered, e.Source, e.Previous)
+					event.Downtime = e.Downtime
+					go Dispatch(logger, event)
+				}
+			case monitor.StateFlapping:
+				go Dispatch(logger, eventFrom(EventFlapping, e.Source, e.Previous))
+			case monitor.StateDown:
+				// a monitor that stopped flapping while down was already alerted on
+				if e.Previous != monitor.StateFlapping {
+					go Dispatch(logger, eventFrom(EventDown, e.Source, e.Previous))
+				}
+			}
+
+		case events.RemediationExhausted:
+			event := eventFrom(EventExhausted, e.Source, e.State)
+			event.Downtime = e.Time.Sub(e.Since)
+			event.Message = fmt.Sprintf("Gave up after %d restarts within %s", e.Attempts, e.Window)
+			if e.Reason != "" {
+				event.Message = "Gave up: " + e.Reason
+			}
+			go Dispatch(logger, event)
+
+		case events.ThresholdBreached:
+			if e.Suppressed != "" {
+				return
+			}
+			event := eventFrom(EventThreshold, e.Source, e.State)
+			event.Message = fmt.Sprintf("%s, currently %.1f", e.Rule, e.Value)
+			go Dispatch(logger, event)
+
+		case events.ThresholdCleared:
+			if e.Suppressed != "" {
+				return
+			}
+			event := eventFrom(EventRecovered, e.Source, e.State)
+			event.Message = fmt.Sprintf("Back below %s, currently %.1f", e.Rule, e.Value)
+			go Dispatch(logger, event)
+
+		case events.RemediationSucceeded:
+			event := eventFrom(EventRestarted, e.Source, e.State)
+			event.Downtime = e.Time.Sub(e.Since)
+			event.Remediation = &Remediation{Action: e.Action, Success: true}
+			go Dispatch(logger, event)
+		}
+	})
+}
+
+func eventFrom(kind EventKind, source events.Source, previous monitor.State) Event {
+	event := NewEvent(kind, source.MonitorType, source.MonitorID, source.MonitorName)
+	event.Critical = source.Critical
+	event.PreviousState = string(previous)
+	event.State = string(source.State)
+	event.Status = source.Status
+	event.Failure = source.Failure
+	event.Health = source.Health
+	event.HealthLog = source.HealthLog
+	event.Drift = source.Drift
+	event.ContainerID = source.ContainerID
+	event.PID = source.PID
+
+	return event
+}
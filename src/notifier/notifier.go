@@ -0,0 +1,358 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is as severe as min.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+type EventKind string
+
+const (
+	EventDown      EventKind = "down"
+	EventRestarted EventKind = "restarted"
+	EventRecovered EventKind = "recovered"
+	// EventFlapping is sent once when a monitor keeps going up and down,
+	// restarts are suspended until it settles
+	EventFlapping EventKind = "flapping"
+	// EventExhausted is sent once when the watchdog gives up restarting a monitor
+	EventExhausted EventKind = "exhausted"
+	// EventThreshold is sent once when a resource rule of a container fires
+	EventThreshold EventKind = "threshold"
+	// EventEscalated is sent by escalation policies to a single user
+	EventEscalated EventKind = "escalated"
+)
+
+// Remediation is the outcome of an action the watchdog took on a monitor.
+type Remediation struct {
+	Action  string
+	Success bool
+	Error   string
+}
+
+// NewRemediation records the outcome of action, failed when err is set.
+func NewRemediation(action string, err error) *Remediation {
+	remediation := &Remediation{Action: action, Success: err == nil}
+	if err != nil {
+		remediation.Error = err.Error()
+	}
+
+	return remediation
+}
+
+func (r Remediation) String() string {
+	if r.Success {
+		return r.Action + " (ok)"
+	}
+
+	return r.Action + " (failed: " + r.Error + ")"
+}
+
+// Event is a single alert about a monitored container or process.
+type Event struct {
+	ID          string
+	Kind        EventKind
+	Severity    Severity
+	MonitorType string
+	MonitorID   string
+	MonitorName string
+	Critical    bool
+	// PreviousState is empty the first time a monitor is checked
+	PreviousState string
+	State         string
+	// Status as reported by docker or pm2
+	Status string
+	// Failure classifies why a container stopped, e.g. oom-killed or crash-loop
+	Failure string
+	// Health is the docker healthcheck status, HealthLog the output of the
+	// latest failed healthcheck
+	Health    string
+	HealthLog string
+	// Drift describes a container running another image than its monitor expects
+	Drift       string
+	ContainerID string
+	PID         int
+	Remediation *Remediation
+	// Downtime is how long the monitor has been down, set on restarted and recovered events
+	Downtime time.Duration
+	Host     string
+	Message  string
+	Time     time.Time
+}
+
+// Subject is a one line summary of the event.
+func (e Event) Subject() string {
+	return fmt.Sprintf("[%s] %s %s is %s", strings.ToUpper(string(e.Severity)), e.MonitorType, e.MonitorName, e.Kind)
+}
+
+// Details lists the event fields as "Label: value" lines.
+func (e Event) Details() string {
+	lines := []string{
+		"Target: " + e.MonitorName,
+		"Type: " + e.MonitorType,
+		"Event: " + string(e.Kind),
+	}
+	if e.Status != "" {
+		lines = append(lines, "Status: "+e.Status)
+	}
+	if e.Failure != "" {
+		lines = append(lines, "Failure: "+e.Failure)
+	}
+	if e.Health != "" {
+		lines = append(lines, "Health: "+e.Health)
+	}
+	if e.HealthLog != "" {
+		lines = append(lines, "Healthcheck output: "+e.HealthLog)
+	}
+	if e.Drift != "" {
+		lines = append(lines, "Drift: "+e.Drift)
+	}
+	if e.ContainerID != "" {
+		lines = append(lines, "Container ID: "+e.ContainerID)
+	}
+	if e.PID != 0 {
+		lines = append(lines, fmt.Sprintf("PID: %d", e.PID))
+	}
+	if e.Remediation != nil {
+		lines = append(lines, "Action: "+e.Remediation.String())
+	}
+	if e.Downtime > 0 {
+		lines = append(lines, "Downtime: "+e.Downtime.Round(time.Second).String())
+	}
+	if e.Message != "" {
+		lines = append(lines, "Message: "+e.Message)
+	}
+	if e.Host != "" {
+		lines = append(lines, "Host: "+e.Host)
+	}
+	lines = append(lines, "Time: "+e.Time.Format(time.RFC3339))
+
+	return strings.Join(lines, "\n")
+}
+
+var hostname, _ = os.Hostname()
+
+// NewEvent creates an event for kind with its default severity.
+func NewEvent(kind EventKind, monitorType string, monitorID string, monitorName string) Event {
+	severity := SeverityInfo
+	switch kind {
+	case EventDown, EventEscalated, EventFlapping, EventExhausted:
+		severity = SeverityCritical
+	case EventRestarted, EventThreshold:
+		severity = SeverityWarning
+	}
+
+	return Event{
+		ID:          uuid.New().String(),
+		Kind:        kind,
+		Severity:    severity,
+		MonitorType: monitorType,
+		MonitorID:   monitorID,
+		MonitorName: monitorName,
+		Host:        hostname,
+		Time:        time.Now(),
+	}
+}
+
+// Recipient is a user that should receive an event, together with the
+// subscription that matched it.
+type Recipient struct {
+	User         db.User
+	Subscription db.Subscription
+}
+
+// Notifier delivers events over a single channel, e.g. email.
+type Notifier interface {
+	Notify(ctx context.Context, recipient Recipient, event Event) error
+}
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+	ChannelSMS     = "sms"
+	ChannelCall    = "call"
+	ChannelPush    = "push"
+)
+
+// destination is implemented by notifiers posting to endpoints that several
+// subscriptions may share, e.g. a team channel, so each gets an event once.
+type destination interface {
+	Destination(recipient Recipient) string
+}
+
+var notifiers = map[string]Notifier{}
+
+var telephony TelephonyProvider
+
+var vapidPublicKey string
+
+// Setup registers every notifier that is configured through the environment.
+func Setup(logger zerolog.Logger) {
+	email, err := NewEmailNotifierFromEnv()
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid SMTP configuration, email alerts disabled")
+	} else if email != nil {
+		Register(ChannelEmail, email)
+		logger.Info().Msgf("Email alerts enabled via %s", email.Addr())
+	}
+
+	twilio, err := NewTwilioProviderFromEnv()
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid telephony configuration, SMS and call alerts disabled")
+	} else if twilio != nil {
+		SetTelephony(twilio)
+		logger.Info().Msgf("SMS and call alerts enabled via %s", twilio.BaseURL)
+	}
+
+	keys, err := LoadVAPIDKeys(logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load VAPID keys, push alerts disabled")
+	} else {
+		subject := os.Getenv("VAPID_SUBJECT")
+		if subject == "" {
+			subject = "mailto:watchdog@localhost"
+		}
+
+		push, err := NewPushNotifier(keys, subject)
+		if err != nil {
+			logger.Error().Err(err).Msg("Invalid VAPID keys, push alerts disabled")
+		} else {
+			vapidPublicKey = keys.PublicKey
+			Register(ChannelPush, push)
+		}
+	}
+
+	Register(ChannelWebhook, NewWebhookNotifier())
+	Register(ChannelSlack, NewSlackNotifier())
+	Register(ChannelDiscord, NewDiscordNotifier())
+}
+
+// SetTelephony enables the SMS and call channels using provider.
+func SetTelephony(provider TelephonyProvider) {
+	telephony = provider
+	Register(ChannelSMS, &SMSNotifier{Provider: provider})
+	Register(ChannelCall, &CallNotifier{Provider: provider})
+}
+
+// Telephony returns the configured telephony provider, or nil.
+func Telephony() TelephonyProvider {
+	return telephony
+}
+
+// VAPIDPublicKey returns the key browsers need to subscribe to push alerts,
+// empty when push is disabled.
+func VAPIDPublicKey() string {
+	return vapidPublicKey
+}
+
+// Register makes a notifier available for subscriptions using channel.
+func Register(channel string, notifier Notifier) {
+	notifiers[channel] = notifier
+}
+
+// Recipients resolves the subscriptions matching the event's monitor and severity.
+func Recipients(event Event) ([]Recipient, error) {
+	var subscriptions []db.Subscription
+	err := db.DB.Preload("User").
+		Where("monitor_type = ? OR (monitor_type = ? AND (monitor_id IS NULL OR monitor_id = ?))",
+			db.MonitorTypeAll, event.MonitorType, event.MonitorID).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := []Recipient{}
+	for _, subscription := range subscriptions {
+		if !event.Severity.AtLeast(Severity(subscription.MinSeverity)) {
+			continue
+		}
+		recipients = append(recipients, Recipient{User: subscription.User, Subscription: subscription})
+	}
+
+	return recipients, nil
+}
+
+// SendTo notifies a single user over channel, regardless of their subscriptions.
+func SendTo(ctx context.Context, channel string, user db.User, event Event) error {
+	notifier, ok := notifiers[channel]
+	if !ok {
+		return fmt.Errorf("no notifier registered for channel %s", channel)
+	}
+
+	return notifier.Notify(ctx, Recipient{User: user}, event)
+}
+
+// Dispatch sends the event to every subscribed user over each of their channels.
+func Dispatch(logger zerolog.Logger, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	recipients, err := Recipients(event)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to resolve alert recipients")
+		return
+	}
+
+	sent := map[string]bool{}
+	for _, recipient := range recipients {
+		for _, channel := range recipient.Subscription.Channels {
+			notifier, ok := notifiers[channel]
+			if !ok {
+				logger.Warn().Msgf("No notifier registered for channel %s", channel)
+				continue
+			}
+
+			// overlapping subscriptions of a user get the event once per channel
+			key := channel + "|user|" + recipient.User.ID
+			if d, ok := notifier.(destination); ok {
+				key = channel + "|" + d.Destination(recipient)
+			}
+			if sent[key] {
+				continue
+			}
+			sent[key] = true
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := notifier.Notify(ctx, recipient, event); err != nil {
+				logger.Error().Err(err).
+					Str("channel", channel).
+					Str("user", recipient.User.ID).
+					Msgf("Failed to notify about %s", event.MonitorName)
+			}
+			cancel()
+		}
+	}
+}
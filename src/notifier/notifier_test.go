@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/rs/zerolog"
+)
+
+// recorder is a notifier keeping the users it notified.
+type recorder struct {
+	mu    sync.Mutex
+	users []string
+}
+
+func (r *recorder) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = append(r.users, recipient.User.ID)
+	return nil
+}
+
+func TestDispatchNotifiesUserOncePerChannel(t *testing.T) {
+	dbtest.Use(t)
+
+	email := &recorder{}
+	previous, registered := notifiers[ChannelEmail]
+	notifiers[ChannelEmail] = email
+	t.Cleanup(func() {
+		if registered {
+			notifiers[ChannelEmail] = previous
+		} else {
+			delete(notifiers, ChannelEmail)
+		}
+	})
+
+	for _, user := range []db.User{
+		{ID: "alice", Name: "Alice", Email: "alice@example.com"},
+		{ID: "bob", Name: "Bob", Email: "bob@example.com"},
+	} {
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	web := "web"
+	for _, subscription := range []db.Subscription{
+		{ID: "all", UserID: "alice", MonitorType: db.MonitorTypeAll, MinSeverity: "info", Channels: []string{ChannelEmail}},
+		{ID: "containers", UserID: "alice", MonitorType: db.MonitorTypeContainer, MinSeverity: "info", Channels: []string{ChannelEmail}},
+		{ID: "web", UserID: "alice", MonitorType: db.MonitorTypeContainer, MonitorID: &web, MinSeverity: "info", Channels: []string{ChannelEmail}},
+		{ID: "bob", UserID: "bob", MonitorType: db.MonitorTypeAll, MinSeverity: "info", Channels: []string{ChannelEmail}},
+	} {
+		if err := db.DB.Omit("User").Create(&subscription).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Dispatch(zerolog.Nop(), NewEvent(EventDown, db.MonitorTypeContainer, "web", "web"))
+
+	count := map[string]int{}
+	for _, user := range email.users {
+		count[user]++
+	}
+	if count["alice"] != 1 || count["bob"] != 1 || len(email.users) != 2 {
+		t.Fatalf("notified %v, want alice and bob once", email.users)
+	}
+}
@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TelephonyProvider sends SMS and places voice calls.
+type TelephonyProvider interface {
+	SendSMS(ctx context.Context, to string, body string) error
+	Call(ctx context.Context, to string, message string) error
+}
+
+// TwilioProvider talks to the Twilio REST API, or anything compatible with
+// it when BaseURL points elsewhere, e.g. a local stub.
+type TwilioProvider struct {
+	BaseURL    string
+	AccountSID string
+	AuthToken  string
+	From       string
+	Client     *http.Client
+}
+
+// NewTwilioProviderFromEnv reads the TWILIO_* variables. It returns nil when
+// TWILIO_ACCOUNT_SID is not set.
+func NewTwilioProviderFromEnv() (*TwilioProvider, error) {
+	accountSID := os.Getenv("TWILIO_ACCOUNT_SID")
+	if accountSID == "" {
+		return nil, nil
+	}
+
+	from := os.Getenv("TWILIO_FROM")
+	if from == "" {
+		return nil, fmt.Errorf("TWILIO_FROM is not set")
+	}
+
+	baseURL := os.Getenv("TWILIO_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.twilio.com"
+	}
+
+	return &TwilioProvider{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		AccountSID: accountSID,
+		AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+		From:       from,
+		Client:     &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (p *TwilioProvider) SendSMS(ctx context.Context, to string, body string) error {
+	return p.post(ctx, "Messages.json", url.Values{
+		"To":   {to},
+		"From": {p.From},
+		"Body": {body},
+	})
+}
+
+func (p *TwilioProvider) Call(ctx context.Context, to string, message string) error {
+	return p.post(ctx, "Calls.json", url.Values{
+		"To":    {to},
+		"From":  {p.From},
+		"Twiml": {sayTwiml(message)},
+	})
+}
+
+func (p *TwilioProvider) post(ctx context.Context, resource string, form url.Values) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s", p.BaseURL, url.PathEscape(p.AccountSID), resource)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telephony provider returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// sayTwiml reads the message out twice so it is not missed on pickup.
+func sayTwiml(message string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(message))
+
+	say := "<Say>" + escaped.String() + "</Say>"
+
+	return "<Response>" + say + `<Pause length="1"/>` + say + "</Response>"
+}
+
+func verifiedPhone(recipient Recipient) (string, error) {
+	if recipient.User.Phone == "" || recipient.User.PhoneVerifiedAt == nil {
+		return "", fmt.Errorf("user %s has no verified phone number", recipient.User.ID)
+	}
+
+	return recipient.User.Phone, nil
+}
+
+// SMSNotifier texts subscribers when a monitor goes down, starts flapping or
+// cannot be restarted, or an incident is escalated to them.
+type SMSNotifier struct {
+	Provider TelephonyProvider
+}
+
+func (n *SMSNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	if event.Kind != EventDown && event.Kind != EventEscalated && event.Kind != EventFlapping && event.Kind != EventExhausted {
+		return nil
+	}
+
+	phone, err := verifiedPhone(recipient)
+	if err != nil {
+		return err
+	}
+
+	body := event.Subject()
+	if event.Remediation != nil {
+		body += ". Action: " + event.Remediation.String()
+	}
+
+	return n.Provider.SendSMS(ctx, phone, body)
+}
+
+// CallNotifier calls subscribers when a critical monitor goes down or an
+// incident is escalated to them.
+type CallNotifier struct {
+	Provider TelephonyProvider
+}
+
+func (n *CallNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	if !(event.Kind == EventDown && event.Critical) && event.Kind != EventEscalated {
+		return nil
+	}
+
+	phone, err := verifiedPhone(recipient)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Watchdog alert. The %s %s on %s is down.", event.MonitorType, event.MonitorName, event.Host)
+	if event.Kind == EventEscalated {
+		message += " The incident has not been acknowledged."
+	}
+
+	return n.Provider.Call(ctx, phone, message)
+}
@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+)
+
+// twilioStub records the requests a TwilioProvider makes.
+type twilioStub struct {
+	server   *httptest.Server
+	requests chan *http.Request
+	forms    chan url.Values
+}
+
+func newTwilioStub(t *testing.T, status int) *twilioStub {
+	t.Helper()
+
+	stub := &twilioStub{requests: make(chan *http.Request, 4), forms: make(chan url.Values, 4)}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		stub.requests <- r
+		stub.forms <- r.PostForm
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"stub"}`))
+	}))
+	t.Cleanup(stub.server.Close)
+
+	return stub
+}
+
+func (s *twilioStub) provider() *TwilioProvider {
+	return &TwilioProvider{
+		BaseURL:    s.server.URL,
+		AccountSID: "AC123",
+		AuthToken:  "token",
+		From:       "+15550000000",
+		Client:     s.server.Client(),
+	}
+}
+
+func (s *twilioStub) received(t *testing.T) (*http.Request, url.Values) {
+	t.Helper()
+
+	select {
+	case r := <-s.requests:
+		return r, <-s.forms
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub received no request")
+	}
+
+	return nil, nil
+}
+
+func (s *twilioStub) idle(t *testing.T) {
+	t.Helper()
+
+	select {
+	case r := <-s.requests:
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	default:
+	}
+}
+
+func verifiedUser() db.User {
+	verifiedAt := time.Now()
+	return db.User{ID: "alice", Phone: "+14155550123", PhoneVerifiedAt: &verifiedAt}
+}
+
+func TestSMSNotifierSendsToStub(t *testing.T) {
+	stub := newTwilioStub(t, http.StatusCreated)
+	notifier := &SMSNotifier{Provider: stub.provider()}
+
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	if err := notifier.Notify(context.Background(), Recipient{User: verifiedUser()}, event); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	r, form := stub.received(t)
+	if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Fatalf("path = %s", r.URL.Path)
+	}
+	if user, password, ok := r.BasicAuth(); !ok || user != "AC123" || password != "token" {
+		t.Fatalf("basic auth = %q, %q", user, password)
+	}
+	if form.Get("To") != "+14155550123" || form.Get("From") != "+15550000000" || form.Get("Body") != event.Subject() {
+		t.Fatalf("form = %v", form)
+	}
+}
+
+func TestSMSNotifierSkips(t *testing.T) {
+	stub := newTwilioStub(t, http.StatusCreated)
+	notifier := &SMSNotifier{Provider: stub.provider()}
+
+	recovered := NewEvent(EventRecovered, db.MonitorTypeContainer, "web", "web")
+	if err := notifier.Notify(context.Background(), Recipient{User: verifiedUser()}, recovered); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	stub.idle(t)
+
+	unverified := db.User{ID: "bob", Phone: "+14155550123"}
+	down := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	if err := notifier.Notify(context.Background(), Recipient{User: unverified}, down); err == nil {
+		t.Fatal("unverified phone numbers should be an error")
+	}
+	stub.idle(t)
+}
+
+func TestCallNotifierSendsTwiml(t *testing.T) {
+	stub := newTwilioStub(t, http.StatusCreated)
+	notifier := &CallNotifier{Provider: stub.provider()}
+
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web <db>")
+	event.Critical = true
+	if err := notifier.Notify(context.Background(), Recipient{User: verifiedUser()}, event); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	r, form := stub.received(t)
+	if r.URL.Path != "/2010-04-01/Accounts/AC123/Calls.json" {
+		t.Fatalf("path = %s", r.URL.Path)
+	}
+	twiml := form.Get("Twiml")
+	if !strings.HasPrefix(twiml, "<Response><Say>") || !strings.Contains(twiml, "web &lt;db&gt;") {
+		t.Fatalf("twiml = %s", twiml)
+	}
+
+	// non critical monitors are only texted
+	event.Critical = false
+	if err := notifier.Notify(context.Background(), Recipient{User: verifiedUser()}, event); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	stub.idle(t)
+}
+
+func TestTwilioProviderReportsErrors(t *testing.T) {
+	stub := newTwilioStub(t, http.StatusBadRequest)
+
+	err := stub.provider().SendSMS(context.Background(), "+14155550123", "hello")
+	if err == nil || !strings.Contains(err.Error(), "stub") {
+		t.Fatalf("SendSMS() = %v, want the provider error", err)
+	}
+}
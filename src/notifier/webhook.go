@@ -0,0 +1,227 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+)
+
+const (
+	SignatureHeader = "X-Watchdog-Signature"
+	TimestampHeader = "X-Watchdog-Timestamp"
+	EventHeader     = "X-Watchdog-Event"
+)
+
+// WebhookPayload is the JSON body posted to webhook endpoints. Fields are
+// only ever added, never renamed, so receivers can rely on them.
+type WebhookPayload struct {
+	Version     int                 `json:"version"`
+	ID          string              `json:"id"`
+	Event       EventKind           `json:"event"`
+	Severity    Severity            `json:"severity"`
+	Monitor     WebhookMonitor      `json:"monitor"`
+	State       WebhookState        `json:"state"`
+	Status      string              `json:"status,omitempty"`
+	Failure     string              `json:"failure,omitempty"`
+	Health      string              `json:"health,omitempty"`
+	HealthLog   string              `json:"healthcheck_output,omitempty"`
+	Drift       string              `json:"drift,omitempty"`
+	ContainerID string              `json:"container_id,omitempty"`
+	PID         int                 `json:"pid,omitempty"`
+	Remediation *WebhookRemediation `json:"remediation"`
+	// DowntimeSeconds is set on restarted and recovered events
+	DowntimeSeconds int64     `json:"downtime_seconds,omitempty"`
+	Host            string    `json:"host,omitempty"`
+	Message         string    `json:"message,omitempty"`
+	OccurredAt      time.Time `json:"occurred_at"`
+	SentAt          time.Time `json:"sent_at"`
+}
+
+type WebhookMonitor struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type WebhookState struct {
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+type WebhookRemediation struct {
+	Action  string `json:"action"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewWebhookPayload(event Event) WebhookPayload {
+	payload := WebhookPayload{
+		Version:  1,
+		ID:       event.ID,
+		Event:    event.Kind,
+		Severity: event.Severity,
+		Monitor: WebhookMonitor{
+			ID:   event.MonitorID,
+			Type: event.MonitorType,
+			Name: event.MonitorName,
+		},
+		State: WebhookState{
+			Previous: event.PreviousState,
+			Current:  event.State,
+		},
+		Status:          event.Status,
+		Failure:         event.Failure,
+		Health:          event.Health,
+		HealthLog:       event.HealthLog,
+		Drift:           event.Drift,
+		ContainerID:     event.ContainerID,
+		PID:             event.PID,
+		DowntimeSeconds: int64(event.Downtime.Seconds()),
+		Host:            event.Host,
+		Message:         event.Message,
+		OccurredAt:      event.Time,
+		SentAt:          time.Now(),
+	}
+
+	if event.Remediation != nil {
+		payload.Remediation = &WebhookRemediation{
+			Action:  event.Remediation.Action,
+			Success: event.Remediation.Success,
+			Error:   event.Remediation.Error,
+		}
+	}
+
+	return payload
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.body" keyed with secret.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookNotifier posts signed JSON payloads to the URL of a subscription,
+// retrying with exponential backoff until a 2xx response.
+type WebhookNotifier struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+func (n *WebhookNotifier) Destination(recipient Recipient) string {
+	return recipient.Subscription.WebhookURL
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	url := recipient.Subscription.WebhookURL
+	if url == "" {
+		return fmt.Errorf("subscription %s has no webhook url", recipient.Subscription.ID)
+	}
+
+	secret, err := webhookSecret(recipient.Subscription)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(NewWebhookPayload(event))
+	if err != nil {
+		return err
+	}
+
+	delay := n.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, url, secret, event, body)
+		if err == nil || attempt >= n.MaxAttempts {
+			break
+		}
+
+		// retries that could not finish before the deadline of the caller are not started
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+n.Client.Timeout {
+			return fmt.Errorf("webhook %s failed after %d attempts, no time left to retry: %w", url, attempt, err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("webhook %s: %w (last error: %s)", url, ctx.Err(), err)
+		}
+
+		delay *= 2
+		if delay > n.MaxDelay {
+			delay = n.MaxDelay
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("webhook %s failed after %d attempts: %w", url, n.MaxAttempts, err)
+	}
+
+	return nil
+}
+
+// webhookSecret returns the HMAC secret of a subscription's webhook.
+func webhookSecret(subscription db.Subscription) (string, error) {
+	if subscription.WebhookSecretEncrypted == "" {
+		return "", nil
+	}
+
+	secret, err := secrets.Decrypt(subscription.WebhookSecretEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the webhook secret of subscription %s: %w", subscription.ID, err)
+	}
+
+	return secret, nil
+}
+
+func (n *WebhookNotifier) post(ctx context.Context, url string, secret string, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event.Kind))
+	req.Header.Set(TimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+)
+
+func useSecretKey(t *testing.T) {
+	t.Setenv("WATCHDOG_SECRET_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, 32)))
+}
+
+func TestWebhookSignsWithEncryptedSecret(t *testing.T) {
+	useSecretKey(t)
+	encrypted, err := secrets.Encrypt("shh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var verified atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := "sha256=" + Sign("shh", r.Header.Get(TimestampHeader), body)
+		verified.Store(r.Header.Get(SignatureHeader) == want)
+	}))
+	defer receiver.Close()
+
+	recipient := Recipient{Subscription: db.Subscription{ID: "sub", WebhookURL: receiver.URL, WebhookSecretEncrypted: encrypted}}
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	if err := NewWebhookNotifier().Notify(context.Background(), recipient, event); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	if !verified.Load() {
+		t.Fatal("payload was not signed with the decrypted secret")
+	}
+}
+
+func TestWebhookRetriesWithinDeadline(t *testing.T) {
+	var attempts atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	notifier := &WebhookNotifier{
+		Client:      &http.Client{Timeout: 100 * time.Millisecond},
+		MaxAttempts: 10,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+	recipient := Recipient{Subscription: db.Subscription{ID: "sub", WebhookURL: receiver.URL}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	err := notifier.Notify(ctx, recipient, NewEvent(EventDown, db.MonitorTypeContainer, "web", "web"))
+	if err == nil || !strings.Contains(err.Error(), "no time left to retry") {
+		t.Fatalf("Notify() = %v, want to stop before the deadline", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Notify should return before the deadline of the caller")
+	}
+	if got := attempts.Load(); got < 2 || got >= 10 {
+		t.Fatalf("made %d attempts", got)
+	}
+}
@@ -0,0 +1,381 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/secrets"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Record size advertised in the aes128gcm header, see RFC 8188
+const pushRecordSize = 4096
+
+// Push services accept bodies up to 4096 bytes, the aes128gcm header with the
+// salt, record size and the 65 byte server key included (RFC 8291, section 4)
+const (
+	pushMaxBody    = 4096
+	pushHeaderSize = 16 + 4 + 1 + 65
+	// the largest payload, leaving room for the record delimiter and GCM tag
+	pushMaxPayload = pushMaxBody - pushHeaderSize - 1 - 16
+)
+
+// ErrPushSubscriptionGone is returned when the push service reports that a
+// subscription expired or was revoked.
+var ErrPushSubscriptionGone = errors.New("push subscription is no longer valid")
+
+var b64 = base64.RawURLEncoding
+
+// VAPIDKeys is the application server key pair, both base64url encoded: the
+// uncompressed P-256 public point and the raw private scalar.
+type VAPIDKeys struct {
+	PublicKey  string
+	PrivateKey string
+}
+
+// GenerateVAPIDKeys creates a new P-256 key pair.
+func GenerateVAPIDKeys() (VAPIDKeys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return VAPIDKeys{}, err
+	}
+
+	return VAPIDKeys{
+		PublicKey:  b64.EncodeToString(key.PublicKey().Bytes()),
+		PrivateKey: b64.EncodeToString(key.Bytes()),
+	}, nil
+}
+
+// LoadVAPIDKeys reads VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY, falling back to
+// a key pair stored in the database, which is generated on first use so
+// browser subscriptions survive restarts. Stored private keys are encrypted
+// with WATCHDOG_SECRET_KEY.
+func LoadVAPIDKeys(logger zerolog.Logger) (VAPIDKeys, error) {
+	public, private := os.Getenv("VAPID_PUBLIC_KEY"), os.Getenv("VAPID_PRIVATE_KEY")
+	if public != "" && private != "" {
+		return VAPIDKeys{PublicKey: public, PrivateKey: private}, nil
+	}
+
+	var stored db.VapidKey
+	err := db.DB.Order("created_at").Limit(1).Find(&stored).Error
+	if err != nil {
+		return VAPIDKeys{}, err
+	}
+
+	if stored.PublicKey != "" {
+		private, err := secrets.Decrypt(stored.PrivateKeyEncrypted)
+		if err != nil {
+			return VAPIDKeys{}, fmt.Errorf("failed to decrypt the VAPID private key: %w", err)
+		}
+		return VAPIDKeys{PublicKey: stored.PublicKey, PrivateKey: private}, nil
+	}
+
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		return VAPIDKeys{}, err
+	}
+
+	encrypted, err := secrets.Encrypt(keys.PrivateKey)
+	if err != nil {
+		return VAPIDKeys{}, fmt.Errorf("failed to encrypt the VAPID private key: %w", err)
+	}
+
+	stored = db.VapidKey{PublicKey: keys.PublicKey, PrivateKeyEncrypted: encrypted}
+	if err := db.DB.Create(&stored).Error; err != nil {
+		return VAPIDKeys{}, err
+	}
+	logger.Info().Msg("Generated a new VAPID key pair")
+
+	return keys, nil
+}
+
+// PushNotifier delivers encrypted Web Push messages to every browser a user
+// registered.
+type PushNotifier struct {
+	Keys    VAPIDKeys
+	Subject string
+	Client  *http.Client
+
+	signingKey *ecdsa.PrivateKey
+}
+
+func NewPushNotifier(keys VAPIDKeys, subject string) (*PushNotifier, error) {
+	signingKey, err := vapidSigningKey(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushNotifier{
+		Keys:       keys,
+		Subject:    subject,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		signingKey: signingKey,
+	}, nil
+}
+
+func (n *PushNotifier) Notify(ctx context.Context, recipient Recipient, event Event) error {
+	var subscriptions []db.PushSubscription
+	if err := db.DB.Where("user_id = ?", recipient.User.ID).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return fmt.Errorf("user %s has no push subscriptions", recipient.User.ID)
+	}
+
+	payload, err := pushPayload(event)
+	if err != nil {
+		return err
+	}
+
+	urgency := "normal"
+	if event.Severity == SeverityCritical {
+		urgency = "high"
+	}
+
+	errs := []error{}
+	for _, subscription := range subscriptions {
+		err := n.Send(ctx, subscription, payload, urgency)
+		if errors.Is(err, ErrPushSubscriptionGone) {
+			db.DB.Delete(&subscription)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pushPayload is the JSON shown by the service worker. The body is cut short
+// when the event details, e.g. a long healthcheck output, do not fit in a
+// push message.
+func pushPayload(event Event) ([]byte, error) {
+	body := event.Details()
+	for {
+		payload, err := json.Marshal(map[string]any{
+			"title":    chatTitle(event),
+			"body":     body,
+			"tag":      event.MonitorID,
+			"event":    event.Kind,
+			"severity": event.Severity,
+			"time":     event.Time,
+		})
+		if err != nil || len(payload) <= pushMaxPayload {
+			return payload, err
+		}
+		if body == "" {
+			return nil, fmt.Errorf("push payload too large: %d bytes", len(payload))
+		}
+
+		// escaping may take more than a byte per character, so the body is cut
+		// in proportion to its encoded size until the payload fits
+		encoded, _ := json.Marshal(body)
+		excess := len(payload) - pushMaxPayload + len("…")
+		keep := len(strings.TrimSuffix(body, "…")) - (excess*len(body)+len(encoded)-1)/len(encoded)
+		if keep <= 0 {
+			body = ""
+			continue
+		}
+		body = strings.ToValidUTF8(body[:keep], "") + "…"
+	}
+}
+
+// Send encrypts payload for the subscription and posts it to its push service.
+func (n *PushNotifier) Send(ctx context.Context, subscription db.PushSubscription, payload []byte, urgency string) error {
+	body, err := EncryptPushPayload(subscription.P256dh, subscription.Auth, payload)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := n.vapidAuthorization(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", "86400")
+	req.Header.Set("Urgency", urgency)
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return ErrPushSubscriptionGone
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// vapidAuthorization builds the "vapid t=<jwt>, k=<key>" header from RFC 8292.
+func (n *PushNotifier) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": n.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + b64.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, n.signingKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", unsigned, b64.EncodeToString(signature), n.Keys.PublicKey), nil
+}
+
+func vapidSigningKey(keys VAPIDKeys) (*ecdsa.PrivateKey, error) {
+	raw, err := b64.DecodeString(keys.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	public := key.PublicKey().Bytes()
+	if b64.EncodeToString(public) != keys.PublicKey {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}, nil
+}
+
+// EncryptPushPayload encrypts payload for a browser subscription following
+// RFC 8291, producing a single aes128gcm record (RFC 8188).
+func EncryptPushPayload(p256dh string, authSecret string, payload []byte) ([]byte, error) {
+	uaPublicBytes, err := b64.DecodeString(strings.TrimRight(p256dh, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	auth, err := b64.DecodeString(strings.TrimRight(authSecret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm, err := hkdfBytes(ecdhSecret, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last (and only) record, 16 bytes are taken by the GCM tag
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if pushHeaderSize+len(plaintext)+gcm.Overhead() > pushMaxBody {
+		return nil, fmt.Errorf("push payload too large: %d bytes", len(payload))
+	}
+
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func hkdfBytes(secret []byte, salt []byte, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,325 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/rs/zerolog"
+)
+
+// browser is the user agent side of a push subscription.
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) browser {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	return browser{key: key, auth: auth}
+}
+
+func (b browser) subscription(endpoint string) db.PushSubscription {
+	return db.PushSubscription{
+		Endpoint: endpoint,
+		P256dh:   b64.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     b64.EncodeToString(b.auth),
+	}
+}
+
+// decrypt opens an aes128gcm body the way a browser does, following RFC 8291.
+func (b browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	if len(body) < pushHeaderSize {
+		t.Fatalf("body of %d bytes is shorter than the header", len(body))
+	}
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != pushRecordSize {
+		t.Fatalf("record size = %d, want %d", rs, pushRecordSize)
+	}
+	if idlen := body[20]; idlen != 65 {
+		t.Fatalf("key id length = %d, want 65", idlen)
+	}
+	asPublicBytes := body[21:86]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatalf("invalid server key: %s", err)
+	}
+	ecdhSecret, err := b.key.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), b.key.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm, err := hkdfBytes(ecdhSecret, b.auth, keyInfo, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hkdfBytes(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, body[pushHeaderSize:], nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt the record: %s", err)
+	}
+
+	// the last record ends with the 0x02 delimiter, optionally followed by padding
+	plaintext = bytes.TrimRight(plaintext, "\x00")
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatal("record is missing the last record delimiter")
+	}
+
+	return plaintext[:len(plaintext)-1]
+}
+
+// verifyVAPID checks the "vapid t=<jwt>, k=<key>" header against the key pair.
+func verifyVAPID(t *testing.T, header string, keys VAPIDKeys, audience string) {
+	t.Helper()
+
+	token, key, found := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !found || key != keys.PublicKey {
+		t.Fatalf("unexpected authorization header %q", header)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	var claims struct {
+		Aud string `json:"aud"`
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+	}
+	raw, err := b64.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		t.Fatalf("invalid claims %q", parts[1])
+	}
+	if claims.Aud != audience || claims.Sub == "" || claims.Exp == 0 {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	public, err := b64.DecodeString(keys.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := b64.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		t.Fatalf("invalid signature %q", parts[2])
+	}
+
+	verifier := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(public[1:33]),
+		Y:     new(big.Int).SetBytes(public[33:]),
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(verifier, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		t.Fatal("VAPID signature does not verify")
+	}
+}
+
+func TestPushSendToStubService(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, err := NewPushNotifier(keys, "mailto:ops@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ua := newBrowser(t)
+	payload := []byte(`{"title":"web is down"}`)
+
+	var received []byte
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") == "" || r.Header.Get("Urgency") != "high" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		verifyVAPID(t, r.Header.Get("Authorization"), keys, "http://"+r.Host)
+
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer service.Close()
+
+	if err := notifier.Send(context.Background(), ua.subscription(service.URL+"/push/abc"), payload, "high"); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	if got := ua.decrypt(t, received); !bytes.Equal(got, payload) {
+		t.Fatalf("decrypted %q, want %q", got, payload)
+	}
+}
+
+func TestPushSendSubscriptionGone(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, err := NewPushNotifier(keys, "mailto:ops@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer service.Close()
+
+	err = notifier.Send(context.Background(), newBrowser(t).subscription(service.URL), []byte("{}"), "normal")
+	if err != ErrPushSubscriptionGone {
+		t.Fatalf("Send() = %v, want ErrPushSubscriptionGone", err)
+	}
+}
+
+func TestEncryptPushPayloadSize(t *testing.T) {
+	ua := newBrowser(t)
+	subscription := ua.subscription("")
+
+	largest := bytes.Repeat([]byte("a"), pushMaxPayload)
+	body, err := EncryptPushPayload(subscription.P256dh, subscription.Auth, largest)
+	if err != nil {
+		t.Fatalf("largest payload: %s", err)
+	}
+	if len(body) != pushMaxBody {
+		t.Fatalf("body is %d bytes, want %d", len(body), pushMaxBody)
+	}
+	if got := ua.decrypt(t, body); !bytes.Equal(got, largest) {
+		t.Fatal("largest payload does not round trip")
+	}
+
+	if _, err := EncryptPushPayload(subscription.P256dh, subscription.Auth, append(largest, 'a')); err == nil {
+		t.Fatal("payload over the body limit should be rejected")
+	}
+}
+
+func TestPushPayloadTruncatesDetails(t *testing.T) {
+	event := NewEvent(EventDown, db.MonitorTypeContainer, "web", "web")
+	event.HealthLog = strings.Repeat("connection refused <\"é\">\n", 400)
+
+	payload, err := pushPayload(event)
+	if err != nil {
+		t.Fatalf("pushPayload: %s", err)
+	}
+	if len(payload) > pushMaxPayload {
+		t.Fatalf("payload is %d bytes, want at most %d", len(payload), pushMaxPayload)
+	}
+
+	var decoded struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(decoded.Body, "Target: web") || !strings.HasSuffix(decoded.Body, "…") {
+		t.Fatalf("body was not cut short: %q", decoded.Body)
+	}
+
+	ua := newBrowser(t)
+	subscription := ua.subscription("")
+	if _, err := EncryptPushPayload(subscription.P256dh, subscription.Auth, payload); err != nil {
+		t.Fatalf("truncated payload does not fit: %s", err)
+	}
+
+	short, err := pushPayload(NewEvent(EventRecovered, db.MonitorTypeContainer, "web", "web"))
+	if err != nil || !strings.Contains(string(short), "Target: web") || strings.Contains(string(short), "…") {
+		t.Fatalf("short payload changed: %s, %v", short, err)
+	}
+}
+
+func TestLoadVAPIDKeysEncryptsStoredKey(t *testing.T) {
+	dbtest.Use(t)
+	t.Setenv("VAPID_PUBLIC_KEY", "")
+	t.Setenv("VAPID_PRIVATE_KEY", "")
+	t.Setenv("WATCHDOG_SECRET_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	logger := zerolog.Nop()
+
+	generated, err := LoadVAPIDKeys(logger)
+	if err != nil {
+		t.Fatalf("LoadVAPIDKeys: %s", err)
+	}
+
+	var stored db.VapidKey
+	if err := db.DB.First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.PrivateKeyEncrypted == "" || strings.Contains(stored.PrivateKeyEncrypted, generated.PrivateKey) {
+		t.Fatalf("private key is not stored encrypted: %+v", stored)
+	}
+
+	loaded, err := LoadVAPIDKeys(logger)
+	if err != nil {
+		t.Fatalf("LoadVAPIDKeys: %s", err)
+	}
+	if loaded != generated {
+		t.Fatal("stored keys do not match the generated ones")
+	}
+	if _, err := NewPushNotifier(loaded, "mailto:ops@example.com"); err != nil {
+		t.Fatalf("stored keys are unusable: %s", err)
+	}
+}
+
+func TestLoadVAPIDKeysNeedsSecretKey(t *testing.T) {
+	dbtest.Use(t)
+	t.Setenv("VAPID_PUBLIC_KEY", "")
+	t.Setenv("VAPID_PRIVATE_KEY", "")
+	t.Setenv("WATCHDOG_SECRET_KEY", "")
+
+	if _, err := LoadVAPIDKeys(zerolog.Nop()); err == nil {
+		t.Fatal("generating keys without WATCHDOG_SECRET_KEY should fail")
+	}
+
+	var count int64
+	db.DB.Model(&db.VapidKey{}).Count(&count)
+	if count != 0 {
+		t.Fatal("no key should be stored in plaintext")
+	}
+}
@@ -0,0 +1,222 @@
+package restart
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/PayCryps/WatchdogGo/src/utils"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type Decision int
+
+const (
+	// Restart means the monitor should be remediated now
+	Restart Decision = iota
+	// Skip means the policy or backoff holds the remediation back
+	Skip
+	// Exhausted means the retry limit was just hit, it is returned once
+	Exhausted
+)
+
+// Defaults reads the global restart policy from the environment:
+// RESTART_MAX_RETRIES, RESTART_WINDOW_MINUTES, RESTART_BACKOFF_INITIAL and
+// RESTART_BACKOFF_MAX (seconds).
+func Defaults(logger zerolog.Logger) db.RestartPolicy {
+	return db.RestartPolicy{
+		Mode:                  db.RestartAlways,
+		MaxRetries:            utils.EnvInt(logger, "RESTART_MAX_RETRIES", 5),
+		WindowMinutes:         utils.EnvInt(logger, "RESTART_WINDOW_MINUTES", 30),
+		InitialBackoffSeconds: utils.EnvInt(logger, "RESTART_BACKOFF_INITIAL", 5),
+		MaxBackoffSeconds:     utils.EnvInt(logger, "RESTART_BACKOFF_MAX", 300),
+	}
+}
+
+// Effective fills the unset fields of a monitor's policy from defaults.
+func Effective(policy db.RestartPolicy, defaults db.RestartPolicy) db.RestartPolicy {
+	if policy.Mode == "" {
+		policy.Mode = defaults.Mode
+	}
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = defaults.MaxRetries
+	}
+	if policy.WindowMinutes == 0 {
+		policy.WindowMinutes = defaults.WindowMinutes
+	}
+	if policy.InitialBackoffSeconds == 0 {
+		policy.InitialBackoffSeconds = defaults.InitialBackoffSeconds
+	}
+	if policy.MaxBackoffSeconds == 0 {
+		policy.MaxBackoffSeconds = defaults.MaxBackoffSeconds
+	}
+
+	return policy
+}
+
+func load(monitorType string, monitorID string) (*db.RestartState, error) {
+	state := &db.RestartState{MonitorType: monitorType, MonitorID: monitorID}
+
+	err := db.DB.First(state, "monitor_type = ? AND monitor_id = ?", monitorType, monitorID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Decide applies the restart policy to a down monitor. failed is false when
+// the monitor exited cleanly. The reason explains skipped restarts.
+func Decide(logger zerolog.Logger, monitorType string, monitorID string, policy db.RestartPolicy, failed bool) (Decision, string) {
+	switch {
+	case policy.Mode == db.RestartNever:
+		return Skip, "restart policy is never"
+	case policy.Mode == db.RestartOnFailure && !failed:
+		return Skip, "exited cleanly and restart policy is on-failure"
+	}
+
+	state, err := load(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to load restart state of %s", monitorID)
+		return Skip, "restart state unavailable"
+	}
+
+	if state.ExhaustedAt != nil {
+		return Skip, "remediation exhausted"
+	}
+
+	now := time.Now()
+	state.Attempts = within(state.Attempts, now, policy)
+	if policy.MaxRetries > 0 && len(state.Attempts) >= policy.MaxRetries {
+		state.ExhaustedAt = &now
+		if err := db.DB.Save(state).Error; err != nil {
+			logger.Error().Err(err).Msgf("Failed to save restart state of %s", monitorID)
+		}
+		return Exhausted, "remediation exhausted"
+	}
+
+	if state.NextAttemptAt != nil && now.Before(*state.NextAttemptAt) {
+		return Skip, "backing off until " + state.NextAttemptAt.Format(time.RFC3339)
+	}
+
+	return Restart, ""
+}
+
+// Record stores a remediation attempt and schedules the earliest next one.
+func Record(logger zerolog.Logger, monitorType string, monitorID string, policy db.RestartPolicy) {
+	state, err := load(monitorType, monitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to load restart state of %s", monitorID)
+		return
+	}
+
+	now := time.Now()
+	state.Attempts = append(within(state.Attempts, now, policy), now)
+	next := now.Add(Backoff(policy, len(state.Attempts)))
+	state.NextAttemptAt = &next
+
+	if err := db.DB.Save(state).Error; err != nil {
+		logger.Error().Err(err).Msgf("Failed to save restart state of %s", monitorID)
+	}
+}
+
+// Reset lifts an exhausted remediation, e.g. once the monitor is up again.
+// The attempts stay counted until they leave the window.
+func Reset(logger zerolog.Logger, monitorType string, monitorID string) {
+	err := db.DB.Model(&db.RestartState{}).
+		Where("monitor_type = ? AND monitor_id = ? AND exhausted_at IS NOT NULL", monitorType, monitorID).
+		Update("exhausted_at", nil).Error
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to reset restart state of %s", monitorID)
+	}
+}
+
+// Clear forgets the whole remediation history of a monitor.
+func Clear(monitorType string, monitorID string) error {
+	return db.DB.Delete(&db.RestartState{}, "monitor_type = ? AND monitor_id = ?", monitorType, monitorID).Error
+}
+
+// Backoff is the delay after the attempt-th attempt: the initial backoff
+// doubled per attempt up to the maximum, with up to half of it as jitter.
+func Backoff(policy db.RestartPolicy, attempt int) time.Duration {
+	delay := time.Duration(policy.InitialBackoffSeconds) * time.Second
+	limit := time.Duration(policy.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// GiveUp stops remediating the down monitor of source until it is up again,
+// e.g. because restarting it cannot help. It publishes RemediationExhausted
+// the first time only.
+func GiveUp(logger zerolog.Logger, source events.Source, reason string) {
+	state, err := load(source.MonitorType, source.MonitorID)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to load restart state of %s", source.MonitorID)
+		return
+	}
+	if state.ExhaustedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	state.ExhaustedAt = &now
+	if err := db.DB.Save(state).Error; err != nil {
+		logger.Error().Err(err).Msgf("Failed to save restart state of %s", source.MonitorID)
+		return
+	}
+
+	logger.Error().Msgf("Giving up on %s %s: %s", source.MonitorType, source.MonitorName, reason)
+	source.Time = now
+	events.Publish(events.RemediationExhausted{Source: source, Attempts: len(state.Attempts), Reason: reason})
+}
+
+func within(attempts []time.Time, now time.Time, policy db.RestartPolicy) []time.Time {
+	window := time.Duration(policy.WindowMinutes) * time.Minute
+
+	recent := []time.Time{}
+	for _, attempt := range attempts {
+		if now.Sub(attempt) < window {
+			recent = append(recent, attempt)
+		}
+	}
+
+	return recent
+}
+
+// Allow applies the restart policy to the down monitor of source and reports
+// whether to remediate it now. Hitting the retry limit is published as
+// RemediationExhausted.
+func Allow(logger zerolog.Logger, source events.Source, policy db.RestartPolicy, failed bool) bool {
+	decision, reason := Decide(logger, source.MonitorType, source.MonitorID, policy, failed)
+
+	switch decision {
+	case Exhausted:
+		logger.Error().Msgf("Giving up on %s %s after %d restarts", source.MonitorType, source.MonitorName, policy.MaxRetries)
+		source.Time = time.Now()
+		events.Publish(events.RemediationExhausted{
+			Source:   source,
+			Attempts: policy.MaxRetries,
+			Window:   time.Duration(policy.WindowMinutes) * time.Minute,
+		})
+		return false
+
+	case Skip:
+		logger.Debug().Msgf("Not remediating %s %s: %s", source.MonitorType, source.MonitorName, reason)
+		return false
+	}
+
+	return true
+}
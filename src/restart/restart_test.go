@@ -0,0 +1,243 @@
+package restart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PayCryps/WatchdogGo/src/db"
+	"github.com/PayCryps/WatchdogGo/src/db/dbtest"
+	"github.com/PayCryps/WatchdogGo/src/events"
+	"github.com/rs/zerolog"
+)
+
+var testPolicy = db.RestartPolicy{
+	Mode:                  db.RestartAlways,
+	MaxRetries:            3,
+	WindowMinutes:         30,
+	InitialBackoffSeconds: 5,
+	MaxBackoffSeconds:     60,
+}
+
+func testSource() events.Source {
+	return events.Source{MonitorType: db.MonitorTypeContainer, MonitorID: "web", MonitorName: "web"}
+}
+
+func loadState(t *testing.T) *db.RestartState {
+	t.Helper()
+
+	state, err := load(db.MonitorTypeContainer, "web")
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+
+	return state
+}
+
+// expire moves the earliest next attempt of the test monitor into the past.
+func expire(t *testing.T) {
+	t.Helper()
+
+	state := loadState(t)
+	past := time.Now().Add(-time.Second)
+	state.NextAttemptAt = &past
+	if err := db.DB.Save(state).Error; err != nil {
+		t.Fatalf("save: %s", err)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	defaults := Defaults(zerolog.Nop())
+
+	if got := Effective(db.RestartPolicy{}, defaults); got != defaults {
+		t.Fatalf("Effective(empty) = %+v, want defaults", got)
+	}
+
+	got := Effective(db.RestartPolicy{Mode: db.RestartOnFailure, MaxRetries: -1}, defaults)
+	if got.Mode != db.RestartOnFailure || got.MaxRetries != -1 || got.WindowMinutes != defaults.WindowMinutes {
+		t.Fatalf("Effective() = %+v", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 1, min: 2500 * time.Millisecond, max: 5 * time.Second},
+		{attempt: 2, min: 5 * time.Second, max: 10 * time.Second},
+		{attempt: 4, min: 20 * time.Second, max: 40 * time.Second},
+		{attempt: 10, min: 30 * time.Second, max: 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := Backoff(testPolicy, tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("Backoff(%d) = %s, want within [%s, %s]", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+
+	if got := Backoff(db.RestartPolicy{}, 3); got != 0 {
+		t.Fatalf("Backoff without delays = %s, want 0", got)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	now := time.Now()
+	attempts := []time.Time{now.Add(-time.Hour), now.Add(-29 * time.Minute), now.Add(-time.Minute)}
+
+	if got := within(attempts, now, testPolicy); len(got) != 2 {
+		t.Fatalf("within() kept %d attempts, want 2", len(got))
+	}
+}
+
+func TestAllowModes(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	tests := []struct {
+		mode   string
+		failed bool
+		want   bool
+	}{
+		{mode: db.RestartAlways, failed: false, want: true},
+		{mode: db.RestartOnFailure, failed: true, want: true},
+		{mode: db.RestartOnFailure, failed: false, want: false},
+		{mode: db.RestartNever, failed: true, want: false},
+	}
+
+	for _, tt := range tests {
+		policy := testPolicy
+		policy.Mode = tt.mode
+		if got := Allow(logger, testSource(), policy, tt.failed); got != tt.want {
+			t.Fatalf("Allow(%s, failed %t) = %t, want %t", tt.mode, tt.failed, got, tt.want)
+		}
+	}
+}
+
+func TestRecordBacksOff(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	if !Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("first restart should be allowed")
+	}
+	Record(logger, db.MonitorTypeContainer, "web", testPolicy)
+
+	state := loadState(t)
+	if len(state.Attempts) != 1 || state.NextAttemptAt == nil || !state.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("recorded state = %+v, want one attempt and a next attempt ahead", state)
+	}
+
+	if Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("restart should wait for the backoff")
+	}
+
+	expire(t)
+	if !Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("restart should be allowed once the backoff passed")
+	}
+}
+
+func TestAllowExhaustsAndReset(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	exhausted, unsubscribe := events.Channel("restart-test")
+	defer unsubscribe()
+
+	for i := 0; i < testPolicy.MaxRetries; i++ {
+		expire(t)
+		if !Allow(logger, testSource(), testPolicy, true) {
+			t.Fatalf("restart %d should be allowed", i+1)
+		}
+		Record(logger, db.MonitorTypeContainer, "web", testPolicy)
+	}
+
+	expire(t)
+	if Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("restart past the retry limit should not be allowed")
+	}
+	if loadState(t).ExhaustedAt == nil {
+		t.Fatal("exhaustion should be persisted")
+	}
+	if !receivedExhausted(exhausted) {
+		t.Fatal("RemediationExhausted should be published")
+	}
+
+	// exhaustion is only published once
+	if Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("exhausted monitor should not be restarted")
+	}
+	if receivedExhausted(exhausted) {
+		t.Fatal("RemediationExhausted should not be published twice")
+	}
+
+	Reset(logger, db.MonitorTypeContainer, "web")
+	state := loadState(t)
+	if state.ExhaustedAt != nil {
+		t.Fatal("Reset should lift the exhaustion")
+	}
+	if len(state.Attempts) != testPolicy.MaxRetries {
+		t.Fatalf("Reset kept %d attempts, want %d", len(state.Attempts), testPolicy.MaxRetries)
+	}
+
+	// the attempts are still within the window, so the limit is hit again
+	if Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("attempts within the window should still count after Reset")
+	}
+}
+
+func TestAttemptsLeaveTheWindow(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	old := time.Now().Add(-time.Duration(testPolicy.WindowMinutes+1) * time.Minute)
+	state := &db.RestartState{MonitorType: db.MonitorTypeContainer, MonitorID: "web", Attempts: []time.Time{old, old, old}}
+	if err := db.DB.Create(state).Error; err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if !Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("attempts outside the window should not count")
+	}
+
+	Record(logger, db.MonitorTypeContainer, "web", testPolicy)
+	if got := len(loadState(t).Attempts); got != 1 {
+		t.Fatalf("Record kept %d attempts, want 1", got)
+	}
+}
+
+func TestGiveUp(t *testing.T) {
+	dbtest.Use(t)
+	logger := zerolog.Nop()
+
+	GiveUp(logger, testSource(), "out of memory")
+	if loadState(t).ExhaustedAt == nil {
+		t.Fatal("GiveUp should persist the exhaustion")
+	}
+	if Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("restart should not be allowed after GiveUp")
+	}
+
+	if err := Clear(db.MonitorTypeContainer, "web"); err != nil {
+		t.Fatalf("Clear: %s", err)
+	}
+	if !Allow(logger, testSource(), testPolicy, true) {
+		t.Fatal("restart should be allowed after Clear")
+	}
+}
+
+func receivedExhausted(published <-chan events.Event) bool {
+	for {
+		select {
+		case event := <-published:
+			if _, ok := event.(events.RemediationExhausted); ok {
+				return true
+			}
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNoKey is returned when WATCHDOG_SECRET_KEY is not set.
+var ErrNoKey = errors.New("WATCHDOG_SECRET_KEY is not set")
+
+// key reads the AES-256 key, 32 bytes encoded in base64, from WATCHDOG_SECRET_KEY.
+func key() ([]byte, error) {
+	encoded := os.Getenv("WATCHDOG_SECRET_KEY")
+	if encoded == "" {
+		return nil, ErrNoKey
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("WATCHDOG_SECRET_KEY must be 32 bytes encoded in base64")
+	}
+
+	return decoded, nil
+}
+
+func aead() (cipher.AEAD, error) {
+	secret, err := key()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals plaintext with AES-256-GCM, the result is the nonce followed
+// by the ciphertext, encoded in base64.
+func Encrypt(plaintext string) (string, error) {
+	gcm, err := aead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt.
+func Decrypt(ciphertext string) (string, error) {
+	gcm, err := aead()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid ciphertext: too short")
+	}
+
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt, was WATCHDOG_SECRET_KEY changed? %w", err)
+	}
+
+	return string(plaintext), nil
+}
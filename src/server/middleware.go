@@ -3,7 +3,10 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
 
+	"github.com/PayCryps/WatchdogGo/src/graph"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,3 +32,29 @@ func GinContextFromContext(ctx context.Context) (*gin.Context, error) {
 	}
 	return gc, nil
 }
+
+// UserMiddleware identifies the calling user from the X-User-ID header. The
+// header is only trusted when TRUST_USER_HEADER is TRUE, i.e. the server runs
+// behind a proxy that authenticates users and sets it, requests carrying it
+// are refused otherwise.
+func UserMiddleware() gin.HandlerFunc {
+	trusted := os.Getenv("TRUST_USER_HEADER") == "TRUE"
+
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		if !trusted {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "X-User-ID is only accepted behind an authenticating proxy",
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(graph.WithUserID(c.Request.Context(), userID))
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func userRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(UserMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return r
+}
+
+func serveUser(r *gin.Engine, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestUserMiddlewareRefusesUntrustedHeader(t *testing.T) {
+	t.Setenv("TRUST_USER_HEADER", "")
+	r := userRouter()
+
+	if w := serveUser(r, "alice"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := serveUser(r, ""); w.Code != http.StatusOK {
+		t.Fatalf("anonymous request: status = %d", w.Code)
+	}
+}
+
+func TestUserMiddlewareTrustsProxyHeader(t *testing.T) {
+	t.Setenv("TRUST_USER_HEADER", "TRUE")
+
+	if w := serveUser(userRouter(), "alice"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
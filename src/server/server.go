@@ -1,6 +1,10 @@
 package server
 
 import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -9,7 +13,10 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/PayCryps/WatchdogGo/src/graph"
+	"github.com/PayCryps/WatchdogGo/src/metrics"
+	"github.com/PayCryps/WatchdogGo/src/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -24,9 +31,15 @@ func HomeHandler(c *gin.Context, startTime time.Time) {
 	})
 }
 
-func graphqlHandler() gin.HandlerFunc {
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+func graphqlHandler(logger zerolog.Logger) gin.HandlerFunc {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{Logger: logger}}))
 
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(),
+		},
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -43,6 +56,27 @@ func graphqlHandler() gin.HandlerFunc {
 	}
 }
 
+// checkOrigin allows websocket upgrades from the same host and from the
+// comma separated origins in GRAPHQL_ALLOWED_ORIGINS.
+func checkOrigin() func(r *http.Request) bool {
+	allowed := []string{}
+	for _, origin := range strings.Split(os.Getenv("GRAPHQL_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowed = append(allowed, origin)
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || utils.Contains(allowed, origin) {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
 func playgroundHandler() gin.HandlerFunc {
 	h := playground.Handler("GraphQL", "/graphql/query")
 
@@ -55,12 +89,17 @@ func RegisterRoutes(r *gin.Engine, logger zerolog.Logger) {
 
 	startTime := time.Now()
 
+	r.Use(GinContextToContextMiddleware())
+	r.Use(UserMiddleware())
+
 	r.Static("/static", "./static")
 
-	r.POST("/graphql/query", graphqlHandler())
+	graphql := graphqlHandler(logger)
+	r.POST("/graphql/query", graphql)
+	// websocket upgrades for subscriptions
+	r.GET("/graphql/query", graphql)
 	r.GET("/graphql", playgroundHandler())
-
-	r.Use(GinContextToContextMiddleware())
+	r.GET("/metrics", metrics.Handler())
 
 	r.GET("/", func(c *gin.Context) {
 		HomeHandler(c, startTime)
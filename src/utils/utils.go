@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -41,3 +42,20 @@ func Contains(slice []string, str string) bool {
 	}
 	return false
 }
+
+// EnvInt reads a non-negative integer from the environment, falling back to
+// fallback when it is unset or invalid.
+func EnvInt(logger zerolog.Logger, name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		logger.Error().Msgf("Invalid %s %q, using %d", name, value, fallback)
+		return fallback
+	}
+
+	return number
+}
@@ -0,0 +1,73 @@
+// Opt in to watchdog push alerts from the dashboard:
+//
+//   <script src="/static/push.js"></script>
+//   watchdogPush.enable()
+//
+// The user is identified by the authenticating proxy in front of the server,
+// which sets X-User-ID on the requests of the signed in user.
+(function () {
+  const endpoint = "/graphql/query";
+
+  async function graphql(query, variables) {
+    const response = await fetch(endpoint, {
+      method: "POST",
+      credentials: "same-origin",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ query, variables }),
+    });
+    const result = await response.json();
+    if (result.errors) {
+      throw new Error(result.errors.map((e) => e.message).join(", "));
+    }
+    return result.data;
+  }
+
+  function decodeKey(base64url) {
+    const base64 = (base64url + "=".repeat((4 - (base64url.length % 4)) % 4)).replace(/-/g, "+").replace(/_/g, "/");
+    return Uint8Array.from(atob(base64), (c) => c.charCodeAt(0));
+  }
+
+  async function enable() {
+    if (!("serviceWorker" in navigator) || !("PushManager" in window)) {
+      throw new Error("push notifications are not supported by this browser");
+    }
+
+    if ((await Notification.requestPermission()) !== "granted") {
+      throw new Error("notification permission was not granted");
+    }
+
+    const { vapidPublicKey } = await graphql("{ vapidPublicKey }");
+    if (!vapidPublicKey) {
+      throw new Error("push alerts are disabled on the server");
+    }
+
+    const registration = await navigator.serviceWorker.register("/static/sw.js");
+    const subscription = await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: decodeKey(vapidPublicKey),
+    });
+
+    const { endpoint, keys } = subscription.toJSON();
+    await graphql(
+      "mutation ($input: PushSubscriptionInput!) { registerPushSubscription(input: $input) { id } }",
+      { input: { endpoint, keys: { p256dh: keys.p256dh, auth: keys.auth } } }
+    );
+
+    return subscription;
+  }
+
+  async function disable() {
+    const registration = await navigator.serviceWorker.getRegistration("/static/sw.js");
+    const subscription = registration && (await registration.pushManager.getSubscription());
+    if (!subscription) {
+      return false;
+    }
+
+    await graphql("mutation ($endpoint: String!) { unregisterPushSubscription(endpoint: $endpoint) }", {
+      endpoint: subscription.endpoint,
+    });
+    return subscription.unsubscribe();
+  }
+
+  window.watchdogPush = { enable, disable };
+})();
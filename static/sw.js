@@ -0,0 +1,37 @@
+// Watchdog service worker: shows the alerts delivered through Web Push.
+
+self.addEventListener("push", (event) => {
+  let alert = { title: "Watchdog alert", body: "" };
+  if (event.data) {
+    try {
+      alert = event.data.json();
+    } catch (e) {
+      alert.body = event.data.text();
+    }
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(alert.title, {
+      body: alert.body,
+      // one notification per monitor, newer events replace older ones
+      tag: alert.tag,
+      renotify: true,
+      requireInteraction: alert.severity === "critical",
+      icon: "/static/favicon.ico",
+      data: alert,
+    })
+  );
+});
+
+self.addEventListener("notificationclick", (event) => {
+  event.notification.close();
+
+  event.waitUntil(
+    self.clients.matchAll({ type: "window", includeUncontrolled: true }).then((windows) => {
+      if (windows.length > 0) {
+        return windows[0].focus();
+      }
+      return self.clients.openWindow("/graphql");
+    })
+  );
+});